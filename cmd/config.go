@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change application settings",
+	Long: fmt.Sprintf(`Read and change the application settings stored in %s.
+
+Settings: %v`, todo.SettingsFilePath(), todo.SettingKeys()),
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every setting",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := todo.ReadSettings(todo.SettingsFilePath())
+		if err != nil {
+			return err
+		}
+		for _, name := range todo.SettingKeys() {
+			value, _ := s.Get(name)
+			fmt.Printf("%s=%s\n", name, value)
+		}
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("invalid settings: %w", err)
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := todo.ReadSettings(todo.SettingsFilePath())
+		if err != nil {
+			return err
+		}
+		value, err := s.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := todo.SettingsFilePath()
+		s, err := todo.ReadSettings(path)
+		if err != nil {
+			return err
+		}
+		if err := s.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		return todo.SaveSettings(path, s)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd)
+	RootCmd.AddCommand(configCmd)
+}
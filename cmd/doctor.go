@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [path/to/note.json]",
+	Short: "Check the note file for problems",
+	Long: `Check the note file for duplicate or invalid IDs, a next_id that isn't
+above the highest ID, priorities other than low, medium and high, due dates
+that aren't YYYY-MM-DD, tasks without text, a UID or a listed context, and
+repeated contexts or UIDs. Each problem is listed with where it is and how it
+would be fixed.
+
+With --fix the problems are repaired, after the file is copied to a
+timestamped .bak file next to it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := dataFileFlag
+		if len(args) > 0 {
+			if dataFileFlag != "" {
+				return errors.New("give the note file either as an argument or with --file, not both")
+			}
+			path = args[0]
+		}
+		path = todo.ResolveDataFile(path, settings)
+
+		if !doctorFix {
+			problems, err := todo.DiagnoseNoteFile(path)
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				fmt.Printf("%s: no problems found\n", path)
+				return nil
+			}
+			printProblems(path, problems)
+			return fmt.Errorf("%d problems found; run 'todo doctor --fix' to repair them", len(problems))
+		}
+
+		problems, backup, err := todo.RepairNoteFile(path)
+		if len(problems) == 0 && err == nil {
+			fmt.Printf("%s: no problems found\n", path)
+			return nil
+		}
+		printProblems(path, problems)
+		if backup != "" {
+			fmt.Printf("Backed up to %s\n", backup)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Fixed %d problems\n", len(problems))
+		return nil
+	},
+}
+
+func printProblems(path string, problems []todo.Problem) {
+	fmt.Printf("%s: %d problems\n", path, len(problems))
+	for _, p := range problems {
+		fmt.Println("  " + p.String())
+	}
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "repair the problems after backing up the file")
+	RootCmd.AddCommand(doctorCmd)
+}
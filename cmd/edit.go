@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var editInEditor bool
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a task",
+	Long: `Edit the task with the given ID. With --editor the task is opened in
+$VISUAL or $EDITOR as a front-matter document; otherwise the TUI starts with
+the task selected and its edit dialog open.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid task ID %q", args[0])
+		}
+		m, err := openModel("")
+		if err != nil {
+			return err
+		}
+		if m.LoadErr != nil {
+			return m.LoadErr
+		}
+		if !m.SelectTask(id) {
+			return fmt.Errorf("no task with ID %d", id)
+		}
+
+		if !editInEditor {
+			task := m.GetCurrentTask()
+			m.ShowInputDialog(todo.EditTaskInput, "Edit task:")
+			m.TextInput.SetValue(task.Task)
+			return runTUI(m)
+		}
+
+		path, err := m.WriteTaskFile(id)
+		if err != nil {
+			return err
+		}
+
+		editor := todo.EditorCommand(path)
+		editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := editor.Run(); err != nil {
+			os.Remove(path)
+			return fmt.Errorf("editor failed: %w", err)
+		}
+		// Keep a file that doesn't validate so the edits aren't lost.
+		if err := m.ApplyTaskFile(id, path); err != nil {
+			return fmt.Errorf("edit not applied: %w; your text is kept in %s", err, path)
+		}
+		os.Remove(path)
+		m.SaveConfig()
+		sendWebhooks(m)
+		if m.ErrorMessage != "" {
+			return errors.New(m.ErrorMessage)
+		}
+		return nil
+	},
+}
+
+func init() {
+	editCmd.Flags().BoolVar(&editInEditor, "editor", false, "edit the task in $VISUAL or $EDITOR")
+	RootCmd.AddCommand(editCmd)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var (
+	exportFormat       string
+	exportOutput       string
+	exportTagSeparator string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the note file to another format",
+	Long: `Export every task of the note file. The format is taken from --format or
+the extension of --output; without --output the export is written to standard
+output.
+
+Formats: ` + strings.Join(todo.FormatNames(), ", "),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := resolveFormat(exportFormat, exportOutput)
+		if err != nil {
+			return err
+		}
+		if f.Export == nil {
+			return fmt.Errorf("%s cannot be exported", f.Name)
+		}
+		m, err := openModel("")
+		if err != nil {
+			return err
+		}
+		if m.LoadErr != nil {
+			return m.LoadErr
+		}
+		if exportTagSeparator != "" {
+			m.Settings.CSVTagSeparator = exportTagSeparator
+		}
+		if exportOutput == "" {
+			return f.Export(os.Stdout, &m)
+		}
+		return m.ExportFile(f, exportOutput)
+	},
+}
+
+// resolveFormat picks the format named by flag, or guesses it from path.
+func resolveFormat(flag, path string) (todo.Format, error) {
+	if flag != "" {
+		return todo.LookupFormat(flag)
+	}
+	if path == "" || path == "-" {
+		return todo.Format{}, fmt.Errorf("--format is required (known: %s)", strings.Join(todo.FormatNames(), ", "))
+	}
+	return todo.FormatForPath(path)
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "output format")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write instead of standard output")
+	exportCmd.Flags().StringVar(&exportTagSeparator, "tag-separator", "", "separator between tags in a CSV field (default csv_tag_separator)")
+	RootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var (
+	importFormat       string
+	importTagSeparator string
+	importColumns      map[string]string
+	importDryRun       bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import tasks from another format",
+	Long: `Add the tasks in file, or standard input if file is "-", to the note
+file. The format is taken from --format or the file's extension. Tasks get
+new IDs, except that a task with the UID of an existing one updates it;
+entries that can't be read are listed and skipped. With --dry-run nothing is
+saved.
+
+For CSV, columns are matched to task fields by their heading; use --column to
+map other headings, e.g. --column Summary=task --column Deadline=due_date.
+
+Formats: ` + strings.Join(todo.FormatNames(), ", "),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		f, err := resolveFormat(importFormat, path)
+		if err != nil {
+			return err
+		}
+		if f.Import == nil {
+			return fmt.Errorf("%s cannot be imported", f.Name)
+		}
+
+		var r io.Reader = os.Stdin
+		if path != "-" {
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			r = file
+		}
+		if importTagSeparator == "" {
+			importTagSeparator = settings.CSVTagSeparator
+		}
+		result, err := f.Import(r, todo.ImportOptions{
+			TagSeparator: importTagSeparator,
+			Columns:      importColumns,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		m, err := openModel("")
+		if err != nil {
+			return err
+		}
+		if m.LoadErr != nil {
+			return m.LoadErr
+		}
+		before := slices.Clone(m.Tasks)
+		counts := m.ImportTasks(result)
+		verb := "Imported"
+		if importDryRun {
+			verb = "Would import"
+		} else {
+			m.SaveConfig()
+			if m.ErrorMessage != "" {
+				fmt.Fprintln(os.Stderr, m.ErrorMessage)
+			}
+			sendWebhooks(m)
+			// Count again: hooks may have refused some of the changes.
+			counts = todo.CountChanges(before, m.Tasks)
+		}
+
+		added, updated := 0, 0
+		for _, c := range counts {
+			added += c.Added
+			updated += c.Updated
+		}
+		if updated > 0 {
+			fmt.Printf("%s %d new and %d updated tasks into %s\n", verb, added, updated, m.ConfigFilePath)
+		} else {
+			fmt.Printf("%s %d tasks into %s\n", verb, added, m.ConfigFilePath)
+		}
+		if unchanged := len(result.Tasks) - added - updated; unchanged > 0 {
+			fmt.Printf("Left %d tasks unchanged\n", unchanged)
+		}
+		printImportSummary(counts)
+		if len(result.Skipped) > 0 {
+			fmt.Printf("Skipped %d:\n", len(result.Skipped))
+			for _, problem := range result.Skipped {
+				fmt.Println("  " + problem)
+			}
+		}
+		return nil
+	},
+}
+
+// printImportSummary lists the tasks added and updated in each context when
+// there is more than one.
+func printImportSummary(counts []todo.ImportCount) {
+	if len(counts) < 2 {
+		return
+	}
+	for _, c := range counts {
+		if c.Updated > 0 {
+			fmt.Printf("  %s: %d new, %d updated\n", c.Context, c.Added, c.Updated)
+		} else {
+			fmt.Printf("  %s: %d\n", c.Context, c.Added)
+		}
+	}
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "format", "", "input format")
+	importCmd.Flags().StringVar(&importTagSeparator, "tag-separator", "", "separator between tags in a CSV field (default csv_tag_separator)")
+	importCmd.Flags().StringToStringVar(&importColumns, "column", nil, "map an input column to a task field, as HEADING=FIELD")
+	importCmd.Flags().BoolVarP(&importDryRun, "dry-run", "n", false, "report what would be imported without changing the note file")
+	RootCmd.AddCommand(importCmd)
+}
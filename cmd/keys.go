@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Print the effective keybindings",
+	Long: fmt.Sprintf(`Print every action with the keys bound to it, after applying the
+overrides from %s.`, todo.KeysFilePath()),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		km, err := todo.LoadKeyMap(todo.KeysFilePath())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ACTION\tKEYS\tDESCRIPTION")
+		for _, action := range km.Actions() {
+			keys := strings.Join(action.Binding.Keys(), " ")
+			if !action.Binding.Enabled() {
+				keys = "(unbound)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", action.Name, keys, action.Binding.Help().Desc)
+		}
+		w.Flush()
+		return err
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(keysCmd)
+}
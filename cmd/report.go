@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	todo "github.com/infraflakes/srn-todo/pkg"
+)
+
+var (
+	reportHTML     string
+	reportTemplate string
+	reportPrint    bool
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a status report",
+	Long: fmt.Sprintf(`Write a self-contained HTML page with completion per context, overdue
+tasks and an index of tags, for sharing in status meetings. Use "-" as the
+file to write to standard output.
+
+The page is rendered with Go's html/template. To brand it, put a template in
+%s or pass one with --template;
+the built-in template is a good starting point:
+
+  todo report --print-template > %s`, todo.ReportTemplatePath(), todo.ReportTemplatePath()),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if reportPrint {
+			fmt.Print(todo.DefaultReportTemplate())
+			return nil
+		}
+		if reportHTML == "" {
+			return errors.New("--html is required")
+		}
+		tmpl, err := todo.LoadReportTemplate(reportTemplate)
+		if err != nil {
+			return err
+		}
+		m, err := openModel("")
+		if err != nil {
+			return err
+		}
+		if m.LoadErr != nil {
+			return m.LoadErr
+		}
+		if reportHTML == "-" {
+			return m.WriteHTMLReport(os.Stdout, tmpl)
+		}
+
+		file, err := os.Create(reportHTML)
+		if err != nil {
+			return err
+		}
+		if err := m.WriteHTMLReport(file, tmpl); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", reportHTML)
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportHTML, "html", "", "HTML file to write")
+	reportCmd.Flags().StringVar(&reportTemplate, "template", "", "template to use instead of "+todo.ReportTemplatePath())
+	reportCmd.Flags().BoolVar(&reportPrint, "print-template", false, "print the built-in template and exit")
+	RootCmd.AddCommand(reportCmd)
+}
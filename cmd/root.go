@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -10,25 +13,111 @@ import (
 	todo "github.com/infraflakes/srn-todo/pkg"
 )
 
+// settings are loaded and validated before any command runs.
+var settings todo.Settings
+
+// dataFileFlag is the persistent --file flag shared by all commands.
+var dataFileFlag string
+
 var RootCmd = &cobra.Command{
 	Use:   "todo [path/to/note.json]",
 	Short: "Manage your todo list",
-	Long:  `A terminal-based todo list manager with contexts, priorities, and more.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `A terminal-based todo list manager with contexts, priorities, and more.
+
+The note file is taken from --file, the optional argument, $SRN_TODO_FILE or
+the data_file setting, and defaults to $XDG_DATA_HOME/srn-todo/note.json.`,
+	Args: cobra.MaximumNArgs(1),
+	// main prints the returned error; usage is only useful for flag errors.
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.HasParent() && cmd.Parent() == configCmd {
+			// config subcommands must work on an invalid file to fix it.
+			return nil
+		}
+		var err error
+		if settings, err = todo.LoadSettings(todo.SettingsFilePath()); err != nil {
+			return fmt.Errorf("invalid settings: %w", err)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		var configPath string
 		if len(args) > 0 {
 			configPath = args[0]
 		}
-		p := tea.NewProgram(todo.Initialize(configPath), tea.WithAltScreen())
-
-		if _, err := p.Run(); err != nil {
+		m, err := openModel(configPath)
+		if err == nil {
+			err = runTUI(m)
+		}
+		if err != nil {
 			fmt.Printf("Error running todo program: %v", err)
 			os.Exit(1)
 		}
 	},
 }
 
+// openModel loads the note file selected by --file, path or the defaults,
+// migrating it from the legacy cache location first if needed.
+func openModel(path string) (todo.Model, error) {
+	if path != "" && dataFileFlag != "" {
+		return todo.Model{}, errors.New("give the note file either as an argument or with --file, not both")
+	}
+	if path == "" {
+		path = dataFileFlag
+	}
+	resolved := todo.ResolveDataFile(path, settings)
+	notice, err := todo.MigrateLegacyDataFile(resolved)
+	if err != nil {
+		return todo.Model{}, fmt.Errorf("migrating note file: %w", err)
+	}
+	if notice != "" {
+		fmt.Fprintln(os.Stderr, notice)
+	}
+
+	m := todo.Initialize(resolved, settings)
+	if notice != "" && m.ErrorMessage == "" {
+		m.ErrorMessage = notice
+	}
+	return m, nil
+}
+
+// sendWebhooks makes one attempt at delivering the webhooks queued by a
+// command; what fails stays queued for the TUI or the next command.
+func sendWebhooks(m todo.Model) {
+	if !m.WebhooksQueued {
+		return
+	}
+	result := todo.DeliverWebhooks(http.DefaultClient, time.Now())
+	for _, failure := range result.Failed {
+		fmt.Fprintln(os.Stderr, "webhook: "+failure)
+	}
+}
+
+func runTUI(m todo.Model) error {
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	if m.Settings.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	m.AsyncHooks = true
+	p := tea.NewProgram(m, opts...)
+	final, err := p.Run()
+	// Save what was still waiting for hooks when the TUI quit.
+	if fm, ok := final.(todo.Model); ok && (fm.HooksQueued || fm.HooksRunning) {
+		fm.ErrorMessage = ""
+		fm.SaveConfigNow()
+		if fm.ErrorMessage != "" {
+			fmt.Fprintln(os.Stderr, fm.ErrorMessage)
+		}
+		sendWebhooks(fm)
+	}
+	return err
+}
+
 func Execute() error {
 	return RootCmd.Execute()
 }
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&dataFileFlag, "file", "", "note file to use")
+}
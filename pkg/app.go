@@ -1,30 +1,34 @@
 package todo
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbletea"
 )
 
-// Initialize creates a new model
-func Initialize(configFilePath string) Model {
-	var finalPath string
-	if configFilePath != "" {
-		finalPath, _ = filepath.Abs(configFilePath)
-	} else {
-		homeDir, _ := os.UserHomeDir()
-		// New default path
-		finalPath = filepath.Join(homeDir, ".cache", "srn-todo", "note.json")
-	}
+// Initialize creates a new model for the note file at configFilePath, which
+// is resolved with ResolveDataFile.
+func Initialize(configFilePath string, settings Settings) Model {
+	finalPath := ResolveDataFile(configFilePath, settings)
 
 	ti := textinput.New()
 	ti.Focus()
-	ti.CharLimit = 200
+	ti.CharLimit = settings.CharLimit
 	ti.Width = 70
 
+	notes := textarea.New()
+	notes.ShowLineNumbers = false
+	notes.CharLimit = 0
+	notes.Placeholder = "Notes..."
+	notes.SetWidth(70)
+	notes.SetHeight(10)
+
 	dateInputs := make([]textinput.Model, 3)
 	for i := range dateInputs {
 		dateInputs[i] = textinput.New()
@@ -33,22 +37,60 @@ func Initialize(configFilePath string) Model {
 		dateInputs[i].Width = 10
 	}
 
+	var warnings []string
+	keyMap, err := LoadKeyMap(KeysFilePath())
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("Using default keys: %v", err))
+	}
+	theme, err := LoadTheme(ThemeFilePath())
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("Using default theme: %v", err))
+	}
+
+	commandInput := textinput.New()
+	commandInput.Prompt = ":"
+
 	m := Model{
 		TextInput:      ti,
+		CommandInput:   commandInput,
+		CommandHistory: LoadCommandHistory(),
+		NotesInput:     notes,
 		DateInputs:     dateInputs,
-		KeyMap:         DefaultKeyMap(),
+		KeyMap:         keyMap,
+		Theme:          theme,
 		Help:           help.New(),
-		ConfigFilePath: finalPath,
-		MaxHistory:     50,
+		MaxHistory:     settings.MaxHistory,
 		ViewMode:       NormalView,
+		Settings:       settings,
 	}
 
-	m.LoadConfig()
-	m.UpdateContexts()
+	m.OpenNoteFile(finalPath)
+	if m.LoadErr != nil {
+		warnings = append(warnings, m.LoadErr.Error())
+	} else if problems, err := DiagnoseNoteFile(finalPath); err == nil && len(problems) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s has %d problems: %s; run 'todo doctor'", filepath.Base(finalPath), len(problems), problems[0]))
+	}
+	m.WebhooksQueued = WebhooksPending()
+	switch settings.DefaultView {
+	case "kanban":
+		m.ShowKanbanView()
+	case "stats":
+		m.ViewMode = StatsView
+	}
+	m.ErrorMessage = strings.Join(warnings, "; ")
 
 	return m
 }
 
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, path[1:])
+	}
+	return path
+}
+
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
 	return textinput.Blink
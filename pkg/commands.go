@@ -0,0 +1,465 @@
+package todo
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Command line. ":" opens an ex-style prompt at the bottom of the screen for
+// actions that don't need a key of their own, e.g. ":ctx Work", ":tag +urgent
+// -later" or ":export json ~/backup.json".
+
+// maxCommandHistory caps the commands remembered across sessions.
+const maxCommandHistory = 100
+
+// command is an entry of the command table. Run receives everything after
+// the command name; Complete returns the possible arguments starting with
+// the one typed so far.
+type command struct {
+	Name     string
+	Usage    string
+	Run      func(m *Model, arg string) (tea.Cmd, error)
+	Complete func(m *Model, arg string) []string
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{Name: "ctx", Usage: "ctx <context>", Run: runCtx, Complete: completeContext},
+		{Name: "mv", Usage: "mv <context>", Run: runMv, Complete: completeContext},
+		{Name: "tag", Usage: "tag [+]<tag> -<tag>...", Run: runTag, Complete: completeTag},
+		{Name: "due", Usage: "due <date>|none", Run: runDue, Complete: completeDue},
+		{Name: "statuses", Usage: "statuses [<status>, <status>...]", Run: runStatuses},
+		{Name: "sort", Usage: "sort due|priority|title|status|created", Run: runSort, Complete: completeSort},
+		{Name: "export", Usage: "export <format> <path>", Run: runExport, Complete: completeExport},
+		{Name: "w", Usage: "w", Run: runWrite},
+		{Name: "q", Usage: "q", Run: runQuit},
+		{Name: "wq", Usage: "wq", Run: runWriteQuit},
+		{Name: "help", Usage: "help", Run: runHelp},
+	}
+}
+
+func lookupCommand(name string) (command, bool) {
+	i := slices.IndexFunc(commands, func(c command) bool { return c.Name == name })
+	if i == -1 {
+		return command{}, false
+	}
+	return commands[i], true
+}
+
+// ShowCommandLine opens the command line.
+func (m *Model) ShowCommandLine() {
+	m.ReturnView = m.ViewMode
+	m.ViewMode = CommandLineView
+	m.CommandInput.SetValue("")
+	m.CommandInput.Focus()
+	m.CommandHistoryIndex = len(m.CommandHistory)
+	m.Completions = nil
+}
+
+// RunCommandLine parses and runs a command, reporting failures in
+// ErrorMessage.
+func (m *Model) RunCommandLine(line string) tea.Cmd {
+	line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	if line == "" {
+		return nil
+	}
+	m.recordCommand(line)
+
+	name, arg, _ := strings.Cut(line, " ")
+	c, ok := lookupCommand(name)
+	if !ok {
+		m.ErrorMessage = fmt.Sprintf("Unknown command %q; try :help", name)
+		return nil
+	}
+	cmd, err := c.Run(m, strings.TrimSpace(arg))
+	if err != nil {
+		m.ErrorMessage = fmt.Sprintf("%s: %v", name, err)
+	}
+	return cmd
+}
+
+// CompleteCommandLine returns the possible completions of line.
+func (m *Model) CompleteCommandLine(line string) []string {
+	name, arg, hasArg := strings.Cut(line, " ")
+	var out []string
+	if !hasArg {
+		for _, c := range commands {
+			if strings.HasPrefix(c.Name, name) {
+				out = append(out, c.Name+" ")
+			}
+		}
+		return out
+	}
+	c, ok := lookupCommand(name)
+	if !ok || c.Complete == nil {
+		return nil
+	}
+	for _, candidate := range c.Complete(m, strings.TrimLeft(arg, " ")) {
+		out = append(out, name+" "+candidate)
+	}
+	return out
+}
+
+// CycleCompletion replaces the command line with the next (or previous)
+// completion.
+func (m *Model) CycleCompletion(delta int) {
+	if m.Completions == nil {
+		m.Completions = m.CompleteCommandLine(m.CommandInput.Value())
+		m.CompletionIndex = -1
+		if len(m.Completions) == 0 {
+			m.Completions = nil
+			return
+		}
+		if delta < 0 {
+			m.CompletionIndex = 0
+		}
+	}
+	n := len(m.Completions)
+	m.CompletionIndex = ((m.CompletionIndex+delta)%n + n) % n
+	m.CommandInput.SetValue(m.Completions[m.CompletionIndex])
+	m.CommandInput.CursorEnd()
+	if n == 1 {
+		m.Completions = nil
+	}
+}
+
+// completeWords returns the options starting with prefix.
+func completeWords(options []string, prefix string) []string {
+	var out []string
+	for _, option := range options {
+		if strings.HasPrefix(strings.ToLower(option), strings.ToLower(prefix)) && !slices.Contains(out, option) {
+			out = append(out, option)
+		}
+	}
+	return out
+}
+
+// completeLastWord completes the last space-separated word of arg.
+func completeLastWord(arg string, options func(word string) []string) []string {
+	head, word := "", arg
+	if i := strings.LastIndex(arg, " "); i != -1 {
+		head, word = arg[:i+1], arg[i+1:]
+	}
+	var out []string
+	for _, option := range options(word) {
+		out = append(out, head+option)
+	}
+	return out
+}
+
+func completeContext(m *Model, arg string) []string {
+	return completeWords(m.Contexts, arg)
+}
+
+func (m *Model) currentTaskIndex() (int, error) {
+	tasks := m.GetFilteredTasks()
+	if len(tasks) == 0 || m.SelectedIndex >= len(tasks) {
+		return -1, fmt.Errorf("no task selected")
+	}
+	return m.findTaskIndexByID(tasks[m.SelectedIndex].ID), nil
+}
+
+func runCtx(m *Model, arg string) (tea.Cmd, error) {
+	context := cleanContextName(arg)
+	if context == "" {
+		return nil, fmt.Errorf("usage: ctx <context>")
+	}
+	if !slices.Contains(m.Contexts, context) {
+		return nil, fmt.Errorf("unknown context %q", context)
+	}
+	m.CurrentContext = context
+	m.SelectedIndex = 0
+	return nil, nil
+}
+
+func runMv(m *Model, arg string) (tea.Cmd, error) {
+	context := cleanContextName(arg)
+	if context == "" {
+		return nil, fmt.Errorf("usage: mv <context>")
+	}
+	idx, err := m.currentTaskIndex()
+	if err != nil {
+		return nil, err
+	}
+	m.SaveStateForUndo()
+	m.Tasks[idx].Context = context
+	m.UpdateContexts()
+	if n := len(m.GetFilteredTasks()); m.SelectedIndex >= n {
+		m.SelectedIndex = max(0, n-1)
+	}
+	m.SaveConfig()
+	return nil, nil
+}
+
+func runTag(m *Model, arg string) (tea.Cmd, error) {
+	words := strings.Fields(arg)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("usage: tag [+]<tag> -<tag>...")
+	}
+	idx, err := m.currentTaskIndex()
+	if err != nil {
+		return nil, err
+	}
+	m.SaveStateForUndo()
+	task := &m.Tasks[idx]
+	for _, word := range words {
+		if tag, ok := strings.CutPrefix(word, "-"); ok {
+			task.Tags = slices.DeleteFunc(slices.Clone(task.Tags), func(t string) bool { return t == tag })
+		} else if tag := strings.TrimPrefix(word, "+"); tag != "" && !slices.Contains(task.Tags, tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	m.SaveConfig()
+	return nil, nil
+}
+
+func completeTag(m *Model, arg string) []string {
+	return completeLastWord(arg, func(word string) []string {
+		var options []string
+		if strings.HasPrefix(word, "-") {
+			for _, tag := range m.GetCurrentTask().Tags {
+				options = append(options, "-"+tag)
+			}
+			return completeWords(options, word)
+		}
+		prefix := ""
+		if strings.HasPrefix(word, "+") {
+			prefix = "+"
+		}
+		for _, task := range m.Tasks {
+			for _, tag := range task.Tags {
+				options = append(options, prefix+tag)
+			}
+		}
+		slices.Sort(options)
+		return completeWords(options, word)
+	})
+}
+
+func runDue(m *Model, arg string) (tea.Cmd, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("usage: due <date>|none")
+	}
+	idx, err := m.currentTaskIndex()
+	if err != nil {
+		return nil, err
+	}
+	date := ""
+	if arg != "none" && arg != "clear" {
+		if date, err = m.Settings.ParseDueDate(arg, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	m.SaveStateForUndo()
+	m.Tasks[idx].DueDate = date
+	m.SaveConfig()
+	return nil, nil
+}
+
+func completeDue(m *Model, arg string) []string {
+	options := []string{"today", "tomorrow", "eow", "nw", "none"}
+	for day := range 7 {
+		options = append(options, strings.ToLower(time.Weekday((day+1)%7).String()))
+	}
+	return completeWords(options, arg)
+}
+
+// runStatuses shows the workflow, or replaces it with a comma-separated list.
+// Tasks whose status is dropped go back to the first status.
+func runStatuses(m *Model, arg string) (tea.Cmd, error) {
+	if arg == "" {
+		m.ErrorMessage = "Statuses: " + strings.Join(m.Statuses, ", ")
+		return nil, nil
+	}
+	var statuses []string
+	for _, status := range strings.Split(arg, ",") {
+		statuses = append(statuses, strings.TrimSpace(status))
+	}
+	if err := validateStatuses(statuses); err != nil {
+		return nil, err
+	}
+	m.Statuses = statuses
+	m.normalizeStatuses()
+	m.SaveConfig()
+	return nil, nil
+}
+
+// taskSorts are the orderings offered by :sort.
+var taskSorts = map[string]func(m *Model, a, b Task) int{
+	"due": func(m *Model, a, b Task) int {
+		// Tasks without a due date go last.
+		if (a.DueDate == "") != (b.DueDate == "") {
+			if a.DueDate == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.DueDate, b.DueDate)
+	},
+	"priority": func(m *Model, a, b Task) int {
+		rank := []string{"high", "medium", "low", ""}
+		return cmp.Compare(slices.Index(rank, a.Priority), slices.Index(rank, b.Priority))
+	},
+	"title": func(m *Model, a, b Task) int {
+		return cmp.Compare(strings.ToLower(a.Task), strings.ToLower(b.Task))
+	},
+	"status": func(m *Model, a, b Task) int {
+		return cmp.Compare(m.StatusIndex(a), m.StatusIndex(b))
+	},
+	"created": func(m *Model, a, b Task) int {
+		return cmp.Compare(a.ID, b.ID)
+	},
+}
+
+func runSort(m *Model, arg string) (tea.Cmd, error) {
+	compare, ok := taskSorts[arg]
+	if !ok {
+		return nil, fmt.Errorf("usage: sort due|priority|title|status|created")
+	}
+	tasks := m.GetFilteredTasks()
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	// Sort the tasks of the current list among the slots they already
+	// occupy, so other contexts keep their order.
+	slots := make([]int, len(tasks))
+	for i, task := range tasks {
+		slots[i] = m.findTaskIndexByID(task.ID)
+	}
+	slices.SortStableFunc(tasks, func(a, b Task) int { return compare(m, a, b) })
+
+	m.SaveStateForUndo()
+	for i, slot := range slots {
+		m.Tasks[slot] = tasks[i]
+	}
+	m.SelectedIndex = 0
+	m.SaveConfig()
+	return nil, nil
+}
+
+func completeSort(m *Model, arg string) []string {
+	options := make([]string, 0, len(taskSorts))
+	for name := range taskSorts {
+		options = append(options, name)
+	}
+	slices.Sort(options)
+	return completeWords(options, arg)
+}
+
+func runExport(m *Model, arg string) (tea.Cmd, error) {
+	name, path, _ := strings.Cut(arg, " ")
+	path = strings.TrimSpace(path)
+	if name == "" || path == "" {
+		return nil, fmt.Errorf("usage: export <format> <path>")
+	}
+	f, err := LookupFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	path = ExpandHome(path)
+	if err := m.ExportFile(f, path); err != nil {
+		return nil, err
+	}
+	m.ErrorMessage = fmt.Sprintf("Exported %s to %s", f.Name, path)
+	return nil, nil
+}
+
+func completeExport(m *Model, arg string) []string {
+	name, path, hasPath := strings.Cut(arg, " ")
+	if !hasPath {
+		var out []string
+		for _, f := range completeWords(FormatNames(), name) {
+			if formats[f].Export != nil {
+				out = append(out, f+" ")
+			}
+		}
+		return out
+	}
+	var out []string
+	for _, p := range completePath(strings.TrimLeft(path, " ")) {
+		out = append(out, name+" "+p)
+	}
+	return out
+}
+
+// completePath lists the files and directories starting with prefix.
+func completePath(prefix string) []string {
+	matches, _ := filepath.Glob(ExpandHome(prefix) + "*")
+	for i, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			matches[i] += string(filepath.Separator)
+		}
+	}
+	return matches
+}
+
+func runWrite(m *Model, arg string) (tea.Cmd, error) {
+	m.SaveConfig()
+	m.ErrorMessage = fmt.Sprintf("Wrote %s", m.ConfigFilePath)
+	return nil, nil
+}
+
+func runQuit(m *Model, arg string) (tea.Cmd, error) {
+	return tea.Quit, nil
+}
+
+func runWriteQuit(m *Model, arg string) (tea.Cmd, error) {
+	m.SaveConfig()
+	return tea.Quit, nil
+}
+
+func runHelp(m *Model, arg string) (tea.Cmd, error) {
+	usages := make([]string, len(commands))
+	for i, c := range commands {
+		usages[i] = ":" + c.Usage
+	}
+	m.ErrorMessage = strings.Join(usages, "  ")
+	return nil, nil
+}
+
+// CommandHistoryPath returns the file holding the command line history.
+func CommandHistoryPath() string {
+	return filepath.Join(StateDir(), "commands.json")
+}
+
+// LoadCommandHistory returns the remembered commands, oldest first.
+func LoadCommandHistory() []string {
+	var history []string
+	data, err := os.ReadFile(CommandHistoryPath())
+	if err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+// recordCommand appends line to the command history and saves it.
+func (m *Model) recordCommand(line string) {
+	m.CommandHistory = slices.DeleteFunc(m.CommandHistory, func(c string) bool {
+		return c == line
+	})
+	m.CommandHistory = append(m.CommandHistory, line)
+	if len(m.CommandHistory) > maxCommandHistory {
+		m.CommandHistory = m.CommandHistory[len(m.CommandHistory)-maxCommandHistory:]
+	}
+	data, err := json.MarshalIndent(m.CommandHistory, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(StateDir(), 0755); err != nil {
+		return
+	}
+	os.WriteFile(CommandHistoryPath(), data, 0644)
+}
@@ -0,0 +1,246 @@
+package todo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// commandModel returns testModel with its note file in an isolated home.
+func commandModel(t *testing.T) *Model {
+	t.Helper()
+	home := isolateHome(t)
+	m := testModel()
+	m.ConfigFilePath = filepath.Join(home, "note.json")
+	m.MaxHistory = 10
+	m.Contexts = append(m.Contexts, "Empty")
+	return m
+}
+
+func TestRunCommandLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		error string // ErrorMessage afterwards; a trailing "*" matches any rest
+		check func(t *testing.T, m *Model)
+	}{
+		{line: "   "},
+		{line: "bogus", error: `Unknown command "bogus"; try :help`},
+		{line: ":ctx Home", check: func(t *testing.T, m *Model) {
+			if m.CurrentContext != "Home" {
+				t.Errorf("context = %q", m.CurrentContext)
+			}
+		}},
+		{line: "ctx", error: "ctx: usage: ctx <context>"},
+		{line: "ctx Nowhere", error: `ctx: unknown context "Nowhere"`},
+		{line: "mv Home", check: func(t *testing.T, m *Model) {
+			if m.Tasks[0].Context != "Home" || len(m.History) != 1 {
+				t.Errorf("task %+v, %d undo entries", m.Tasks[0], len(m.History))
+			}
+		}},
+		{line: "mv New/Sub", check: func(t *testing.T, m *Model) {
+			if m.Tasks[0].Context != "New/Sub" || !slices.Contains(m.Contexts, "New/Sub") {
+				t.Errorf("task %+v, contexts %q", m.Tasks[0], m.Contexts)
+			}
+		}},
+		{line: "mv", error: "mv: usage: mv <context>"},
+		{line: "tag +urgent -q3 deploy later", check: func(t *testing.T, m *Model) {
+			if want := []string{"deploy", "urgent", "later"}; !slices.Equal(m.Tasks[0].Tags, want) {
+				t.Errorf("tags = %q, want %q", m.Tasks[0].Tags, want)
+			}
+		}},
+		{line: "tag", error: "tag: usage: tag [+]<tag> -<tag>..."},
+		{line: "due 2026-11-01", check: func(t *testing.T, m *Model) {
+			if m.Tasks[0].DueDate != "2026-11-01" {
+				t.Errorf("due = %q", m.Tasks[0].DueDate)
+			}
+		}},
+		{line: "due none", check: func(t *testing.T, m *Model) {
+			if m.Tasks[0].DueDate != "" {
+				t.Errorf("due = %q", m.Tasks[0].DueDate)
+			}
+		}},
+		{line: "due", error: "due: usage: due <date>|none"},
+		{line: "due someday", error: "due: *"},
+		{line: "statuses", error: "Statuses: Todo, In Progress, Review, Done"},
+		{line: "statuses Open", error: "statuses: *"},
+		{line: "sort size", error: "sort: usage: sort due|priority|title|status|created"},
+		{line: "export json", error: "export: usage: export <format> <path>"},
+		{line: "export nope out.txt", error: "export: *"},
+		{line: "w", error: "Wrote *"},
+		{line: "help", error: ":ctx <context>  :mv <context>  *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			m := commandModel(t)
+			m.RunCommandLine(tt.line)
+			if prefix, ok := strings.CutSuffix(tt.error, "*"); ok {
+				if !strings.HasPrefix(m.ErrorMessage, prefix) || m.ErrorMessage == prefix {
+					t.Errorf("ErrorMessage = %q, want %q", m.ErrorMessage, tt.error)
+				}
+			} else if m.ErrorMessage != tt.error {
+				t.Errorf("ErrorMessage = %q, want %q", m.ErrorMessage, tt.error)
+			}
+			if tt.check != nil {
+				tt.check(t, m)
+			}
+		})
+	}
+}
+
+func TestRunCommandLineWithoutTask(t *testing.T) {
+	for _, line := range []string{"mv Home", "tag x", "due today"} {
+		m := commandModel(t)
+		m.RunCommandLine("ctx Empty")
+		m.RunCommandLine(line)
+		name, _, _ := strings.Cut(line, " ")
+		if want := name + ": no task selected"; m.ErrorMessage != want {
+			t.Errorf("%s: ErrorMessage = %q, want %q", line, m.ErrorMessage, want)
+		}
+	}
+}
+
+func TestRunSort(t *testing.T) {
+	// Ties keep their order.
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"title", []string{"b", "C", "d", "e"}},
+		{"priority", []string{"d", "e", "C", "b"}},
+		{"due", []string{"C", "e", "d", "b"}},
+		{"status", []string{"e", "C", "d", "b"}},
+		{"created", []string{"b", "C", "d", "e"}},
+	}
+	for _, tt := range tests {
+		m := commandModel(t)
+		m.Tasks = []Task{
+			{ID: 4, Task: "e", Context: "Work", Priority: "medium", DueDate: "2026-10-21", Status: "Todo"},
+			{ID: 9, Task: "Other", Context: "Home"},
+			{ID: 3, Task: "d", Context: "Work", Priority: "high", Status: "Review"},
+			{ID: 2, Task: "C", Context: "Work", Priority: "medium", DueDate: "2026-10-20", Status: "In Progress"},
+			{ID: 1, Task: "b", Context: "Work", Status: "Review"},
+		}
+		m.RunCommandLine("sort " + tt.order)
+		if m.ErrorMessage != "" {
+			t.Fatalf("sort %s: %s", tt.order, m.ErrorMessage)
+		}
+		var got []string
+		for _, task := range m.GetFilteredTasks() {
+			got = append(got, task.Task)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sort %s = %q, want %q", tt.order, got, tt.want)
+		}
+		if m.Tasks[1].Task != "Other" {
+			t.Errorf("sort %s moved another context's task: %+v", tt.order, m.Tasks)
+		}
+	}
+}
+
+func TestCompleteCommandLine(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"notes.json", "notes.ics"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "notebook"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sep := string(filepath.Separator)
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"s", []string{"statuses ", "sort "}},
+		{"w", []string{"w ", "wq "}},
+		{"x", nil},
+		{"ctx ", []string{"ctx Work", "ctx Home", "ctx Empty"}},
+		{"ctx h", []string{"ctx Home"}},
+		{"mv  e", []string{"mv Empty"}},
+		{"tag -", []string{"tag -deploy", "tag -q3"}},
+		{"tag +d", []string{"tag +deploy"}},
+		{"tag urgent q", []string{"tag urgent q3"}},
+		{"due to", []string{"due today", "due tomorrow"}},
+		{"due f", []string{"due friday"}},
+		{"sort p", []string{"sort priority"}},
+		{"export ic", []string{"export ics "}},
+		{"export ics " + dir + sep + "note", []string{
+			"export ics " + filepath.Join(dir, "notebook") + sep,
+			"export ics " + filepath.Join(dir, "notes.ics"),
+			"export ics " + filepath.Join(dir, "notes.json"),
+		}},
+		{"statuses T", nil},
+		{"bogus x", nil},
+	}
+	for _, tt := range tests {
+		m := commandModel(t)
+		if got := m.CompleteCommandLine(tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("CompleteCommandLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestCommandLineKeys(t *testing.T) {
+	m := kanbanTestModel(t)
+	m = press(m, ":", "s")
+	m = press(m, "tab")
+	if got := m.CommandInput.Value(); got != "statuses " {
+		t.Errorf("first completion = %q", got)
+	}
+	m = press(m, "tab")
+	if got := m.CommandInput.Value(); got != "sort " {
+		t.Errorf("second completion = %q", got)
+	}
+	m = press(m, "shift+tab")
+	if got := m.CommandInput.Value(); got != "statuses " {
+		t.Errorf("completion after shift+tab = %q", got)
+	}
+
+	// Errors are shown on the board the command line was opened from.
+	m = press(m, "esc", ":", "bogus", "enter")
+	if m.ViewMode != KanbanView || !strings.Contains(m.View(), `Unknown command "bogus"`) {
+		t.Errorf("view %v:\n%s", m.ViewMode, m.View())
+	}
+
+	m = press(m, ":", "statuses", "enter", ":")
+	for _, want := range []string{"statuses", "bogus", "statuses"} {
+		m = press(m, "up")
+		if got := m.CommandInput.Value(); got != want {
+			t.Errorf("history entry %q, want %q", got, want)
+		}
+		if want == "bogus" {
+			break
+		}
+	}
+	m = press(m, "down")
+	m = press(m, "down")
+	if got := m.CommandInput.Value(); got != "" {
+		t.Errorf("past the newest entry = %q", got)
+	}
+}
+
+func TestCommandHistory(t *testing.T) {
+	m := commandModel(t)
+	for _, line := range []string{"ctx Home", "statuses", ":ctx Home  ", "help"} {
+		m.RunCommandLine(line)
+	}
+	want := []string{"statuses", "ctx Home", "help"}
+	if !slices.Equal(m.CommandHistory, want) {
+		t.Errorf("history = %q, want %q", m.CommandHistory, want)
+	}
+	if got := LoadCommandHistory(); !slices.Equal(got, want) {
+		t.Errorf("saved history = %q, want %q", got, want)
+	}
+
+	for i := range maxCommandHistory + 5 {
+		m.RunCommandLine(fmt.Sprintf("bogus%d", i))
+	}
+	if got := LoadCommandHistory(); len(got) != maxCommandHistory || got[len(got)-1] != "bogus104" {
+		t.Errorf("saved %d commands, newest %q", len(got), got[len(got)-1])
+	}
+}
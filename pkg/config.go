@@ -2,13 +2,94 @@ package todo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 // Configuration and persistence
 
+// DataDir returns the srn-todo directory under $XDG_DATA_HOME, falling back
+// to ~/.local/share.
+func DataDir() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" || !filepath.IsAbs(dir) {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dir, "srn-todo")
+}
+
+// DefaultDataFile returns the note file used when nothing else is configured.
+func DefaultDataFile() string {
+	return filepath.Join(DataDir(), "note.json")
+}
+
+// legacyDataFile is where older versions kept the note file.
+func legacyDataFile() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cache", "srn-todo", "note.json")
+}
+
+// ResolveDataFile picks the note file to use, in order of precedence: the
+// given path (from --file or the command line), $SRN_TODO_FILE, the data_file
+// setting and finally DefaultDataFile.
+func ResolveDataFile(path string, settings Settings) string {
+	for _, candidate := range []string{path, os.Getenv("SRN_TODO_FILE"), settings.DataFile} {
+		if candidate != "" {
+			abs, err := filepath.Abs(ExpandHome(candidate))
+			if err != nil {
+				return candidate
+			}
+			return abs
+		}
+	}
+	return DefaultDataFile()
+}
+
+// MigrateLegacyDataFile moves a note file left in ~/.cache by older versions
+// to path, if path is the default location and doesn't exist yet. It returns
+// a message describing the move, or "" if nothing was done.
+func MigrateLegacyDataFile(path string) (string, error) {
+	legacy := legacyDataFile()
+	if path != DefaultDataFile() {
+		return "", nil
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return "", nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		// Cache and data dirs may live on different filesystems.
+		data, err := os.ReadFile(legacy)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return "", err
+		}
+		os.Remove(legacy)
+	}
+	return fmt.Sprintf("Moved your todo list from %s to %s", legacy, path), nil
+}
+
+// noteFile is the on-disk layout of a note file.
+type noteFile struct {
+	Tasks    []Task   `json:"tasks"`
+	NextID   int      `json:"next_id"`
+	Contexts []string `json:"contexts"`
+	Statuses []string `json:"statuses,omitempty"`
+
+	ContextMeta map[string]ContextMeta `json:"context_meta,omitempty"`
+}
+
 func (m *Model) LoadConfig() {
 	configDir := filepath.Dir(m.ConfigFilePath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
@@ -17,25 +98,39 @@ func (m *Model) LoadConfig() {
 		return
 	}
 
+	m.LoadErr = nil
 	data, err := os.ReadFile(m.ConfigFilePath)
-	if err != nil {
+	if errors.Is(err, os.ErrNotExist) {
 		m.CreateDefaultConfig()
 		return
 	}
-	var config struct {
-		Tasks    []Task   `json:"tasks"`
-		NextID   int      `json:"next_id"`
-		Contexts []string `json:"contexts"`
+	var config noteFile
+	if err == nil {
+		err = json.Unmarshal(data, &config)
 	}
-
-	if err := json.Unmarshal(data, &config); err != nil {
-		m.CreateDefaultConfig()
+	if err != nil {
+		// Open an empty, read-only list rather than defaults that the next
+		// save would write over the user's data.
+		m.LoadErr = fmt.Errorf("%s can't be read, so changes won't be saved; run 'todo doctor' to see why: %w", m.ConfigFilePath, err)
+		m.ErrorMessage = m.LoadErr.Error()
+		m.Tasks, m.Contexts, m.NextID = nil, nil, 1
+		m.Statuses = slices.Clone(DefaultStatuses)
+		m.normalizeStatuses()
+		m.SavedTasks = nil
 		return
 	}
 
 	m.Tasks = config.Tasks
 	m.NextID = config.NextID
 	m.Contexts = config.Contexts
+	m.ContextMeta = config.ContextMeta
+	m.Statuses = config.Statuses
+	m.normalizeStatuses()
+	// Tasks from before UIDs existed get them in memory only, derived from
+	// their IDs so every load agrees; loading never writes the file, and the
+	// next save keeps them.
+	m.assignMissingUIDs()
+	m.SavedTasks = slices.Clone(m.Tasks)
 
 	if m.NextID == 0 {
 		maxID := 0
@@ -48,23 +143,62 @@ func (m *Model) LoadConfig() {
 	}
 }
 
+// noteFile returns the model's persistent state.
+func (m *Model) noteFile() noteFile {
+	return noteFile{
+		Tasks:    m.Tasks,
+		NextID:   m.NextID,
+		Contexts: m.Contexts,
+		Statuses: m.Statuses,
+
+		ContextMeta: m.ContextMeta,
+	}
+}
+
+// SaveConfig saves the note file after running the hooks for the changed
+// tasks. In the TUI the hooks run in the background and the file is written
+// when they finish; see updateHooks.
 func (m *Model) SaveConfig() {
+	if m.LoadErr != nil {
+		m.ErrorMessage = m.LoadErr.Error()
+		return
+	}
+	if m.AsyncHooks {
+		m.HooksQueued = true
+		return
+	}
+	m.SaveConfigNow()
+}
+
+// SaveConfigNow saves the note file, running the hooks before returning even
+// in the TUI, for when the model is about to be replaced or the program is
+// exiting. The result of hooks still running in the background is ignored.
+func (m *Model) SaveConfigNow() {
+	if m.LoadErr != nil {
+		m.ErrorMessage = m.LoadErr.Error()
+		return
+	}
+	m.HookRound++
+	m.HooksQueued, m.HooksRunning = false, false
+	saved, before := m.SavedTasks, slices.Clone(m.Tasks)
+	outcome := m.hookRunner().run(saved, before)
+	m.applyHookOutcome(saved, before, outcome)
+	m.writeNoteFile(outcome.Tasks)
+}
+
+// writeNoteFile writes tasks and the rest of the model's state to the note
+// file and queues webhooks for the changes.
+func (m *Model) writeNoteFile(tasks []Task) {
+	changes := diffTasks(m.SavedTasks, tasks)
 	configDir := filepath.Dir(m.ConfigFilePath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		fmt.Println("Error creating config directory:", err)
 		return
 	}
-	config := struct {
-		Tasks    []Task   `json:"tasks"`
-		NextID   int      `json:"next_id"`
-		Contexts []string `json:"contexts"`
-	}{
-		Tasks:    m.Tasks,
-		NextID:   m.NextID,
-		Contexts: m.Contexts,
-	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+	file := m.noteFile()
+	file.Tasks = tasks
+	data, err := json.MarshalIndent(file, "", "  ")
 	if err != nil {
 		fmt.Println("Error marshaling config:", err)
 		return
@@ -72,7 +206,10 @@ func (m *Model) SaveConfig() {
 
 	if err := os.WriteFile(m.ConfigFilePath, data, 0644); err != nil {
 		fmt.Println("Error saving config file:", err)
+		return
 	}
+	m.SavedTasks = slices.Clone(tasks)
+	m.queueWebhooks(changes)
 }
 
 func (m *Model) CreateDefaultConfig() {
@@ -84,5 +221,9 @@ func (m *Model) CreateDefaultConfig() {
 		{ID: 5, Task: "Press '?' to see more keybindings", Checked: false, Context: "Getting Started"},
 	}
 	m.Contexts = []string{"Getting Started"}
+	m.Statuses = slices.Clone(DefaultStatuses)
 	m.NextID = 6
+	m.normalizeStatuses()
+	m.assignMissingUIDs()
+	m.SavedTasks = slices.Clone(m.Tasks)
 }
@@ -0,0 +1,103 @@
+package todo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigDoesNotWrite(t *testing.T) {
+	home := isolateHome(t)
+	path := filepath.Join(home, "note.json")
+	data := []byte(`{"tasks": [{"id": 1, "task": "Old task", "context": "Work"}], "next_id": 2, "contexts": ["Work"]}`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Model{ConfigFilePath: path, Settings: DefaultSettings()}
+	m.LoadConfig()
+	if m.Tasks[0].UID == "" {
+		t.Fatal("task without a UID did not get one")
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Fatalf("loading rewrote the file:\n%s", got)
+	}
+
+	m.SaveConfig()
+	if got, _ := os.ReadFile(path); !strings.Contains(string(got), m.Tasks[0].UID) {
+		t.Fatalf("saving did not keep the UID:\n%s", got)
+	}
+}
+
+func TestLoadConfigInvalidFileIsReadOnly(t *testing.T) {
+	home := isolateHome(t)
+	path := filepath.Join(home, "note.json")
+	data := []byte(`{"tasks": [{"id": 1, "task": "Keep me"`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Model{ConfigFilePath: path, Settings: DefaultSettings()}
+	m.LoadConfig()
+	if m.LoadErr == nil || len(m.Tasks) != 0 {
+		t.Fatalf("LoadErr = %v, tasks %+v", m.LoadErr, m.Tasks)
+	}
+	m.AddTask("New task")
+	m.ErrorMessage = ""
+	m.SaveConfig()
+	if !strings.Contains(m.ErrorMessage, "todo doctor") {
+		t.Errorf("ErrorMessage = %q", m.ErrorMessage)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Fatalf("saving overwrote the file:\n%s", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"tasks": [], "next_id": 1, "contexts": ["Work"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.LoadConfig()
+	if m.LoadErr != nil {
+		t.Errorf("LoadErr = %v after the file was fixed", m.LoadErr)
+	}
+}
+
+func TestLegacyUIDsAreStableAcrossExports(t *testing.T) {
+	home := isolateHome(t)
+	path := filepath.Join(home, "note.json")
+	data := []byte(`{"tasks": [{"id": 1, "task": "One", "context": "Work"}, {"id": 2, "task": "Two", "context": "Work"}], "next_id": 3, "contexts": ["Work"]}`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LookupFormat("ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	export := func() []string {
+		m := Model{ConfigFilePath: path, Settings: DefaultSettings()}
+		m.LoadConfig()
+		var buf bytes.Buffer
+		if err := f.Export(&buf, &m); err != nil {
+			t.Fatal(err)
+		}
+		var uids []string
+		for _, line := range strings.Split(buf.String(), "\r\n") {
+			if uid, ok := strings.CutPrefix(line, "UID:"); ok {
+				uids = append(uids, uid)
+			}
+		}
+		return uids
+	}
+
+	first, second := export(), export()
+	if len(first) != 2 || first[0] == first[1] {
+		t.Fatalf("UIDs %q", first)
+	}
+	if strings.Join(first, " ") != strings.Join(second, " ") {
+		t.Errorf("exports gave UIDs %q, then %q", first, second)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Errorf("exporting rewrote the file:\n%s", got)
+	}
+}
@@ -0,0 +1,308 @@
+package todo
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Context order and metadata. Model.Contexts is kept in the order the user
+// arranged it; archived contexts keep their tasks but are left out of the
+// tab bar, the h/l cycle and the kanban board unless ShowArchived is set.
+
+// VisibleContexts returns the contexts that can currently be navigated to.
+func (m *Model) VisibleContexts() []string {
+	if m.ShowArchived {
+		return m.Contexts
+	}
+	var visible []string
+	for _, context := range m.Contexts {
+		if !m.contextArchived(context) {
+			visible = append(visible, context)
+		}
+	}
+	return visible
+}
+
+// ensureVisibleContext moves off the current context if it has been hidden.
+func (m *Model) ensureVisibleContext() {
+	visible := m.VisibleContexts()
+	if len(visible) > 0 && !slices.Contains(visible, m.CurrentContext) {
+		m.CurrentContext = visible[0]
+		m.SelectedIndex = 0
+	}
+}
+
+// setContextMeta stores meta for context, dropping entries left empty.
+func (m *Model) setContextMeta(context string, meta ContextMeta) {
+	if meta == (ContextMeta{}) {
+		delete(m.ContextMeta, context)
+		return
+	}
+	if m.ContextMeta == nil {
+		m.ContextMeta = make(map[string]ContextMeta)
+	}
+	m.ContextMeta[context] = meta
+}
+
+// MoveCurrentContext moves the current context delta places along the visible
+// contexts, leaving hidden ones where they are.
+func (m *Model) MoveCurrentContext(delta int) {
+	visible := m.VisibleContexts()
+	i := slices.Index(visible, m.CurrentContext)
+	j := i + delta
+	if i == -1 || j < 0 || j >= len(visible) {
+		return
+	}
+	idx := slices.Index(m.Contexts, m.CurrentContext)
+	m.Contexts = slices.Delete(m.Contexts, idx, idx+1)
+	target := slices.Index(m.Contexts, visible[j])
+	if delta > 0 {
+		target++
+	}
+	m.Contexts = slices.Insert(m.Contexts, target, m.CurrentContext)
+}
+
+// ToggleArchiveCurrentContext archives or restores the current context.
+func (m *Model) ToggleArchiveCurrentContext() {
+	meta := m.ContextMeta[m.CurrentContext]
+	visible := m.VisibleContexts()
+	remaining := slices.DeleteFunc(slices.Clone(visible), func(c string) bool {
+		return c == m.CurrentContext || isSubContext(c, m.CurrentContext)
+	})
+	if !meta.Archived && !m.ShowArchived && len(remaining) == 0 {
+		m.ErrorMessage = "Cannot archive the last visible context"
+		return
+	}
+
+	idx := slices.Index(visible, m.CurrentContext)
+	meta.Archived = !meta.Archived
+	m.setContextMeta(m.CurrentContext, meta)
+	if meta.Archived && !m.ShowArchived {
+		visible = m.VisibleContexts()
+		m.CurrentContext = visible[max(0, min(idx, len(visible)-1))]
+		m.SelectedIndex = 0
+	}
+}
+
+// ToggleShowArchived shows or hides archived contexts.
+func (m *Model) ToggleShowArchived() {
+	m.ShowArchived = !m.ShowArchived
+	m.ensureVisibleContext()
+}
+
+// SetCurrentContextDescription sets the description shown under the tab bar.
+func (m *Model) SetCurrentContextDescription(description string) {
+	meta := m.ContextMeta[m.CurrentContext]
+	meta.Description = description
+	m.setContextMeta(m.CurrentContext, meta)
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor accepts "#RGB", "#RRGGBB" or an ANSI colour number.
+func validColor(color string) bool {
+	if hexColor.MatchString(color) {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// SetCurrentContextColor sets the colour of the current context's tab and
+// kanban header. An empty colour restores the theme's.
+func (m *Model) SetCurrentContextColor(color string) {
+	if color != "" && !validColor(color) {
+		m.ErrorMessage = fmt.Sprintf("Invalid colour %q: use #RRGGBB or 0-255", color)
+		return
+	}
+	meta := m.ContextMeta[m.CurrentContext]
+	meta.Color = color
+	m.setContextMeta(m.CurrentContext, meta)
+}
+
+// contextColor returns the colour set for context, if any. Monochrome themes
+// ignore it.
+func (m *Model) contextColor(context string) (lipgloss.Color, bool) {
+	color := m.ContextMeta[context].Color
+	if color == "" || m.Theme.Name == "none" {
+		return "", false
+	}
+	return lipgloss.Color(color), true
+}
+
+// contextStyle is the theme's context style in the context's own colour.
+func (m *Model) contextStyle(context string) lipgloss.Style {
+	style := m.Theme.Context
+	if color, ok := m.contextColor(context); ok {
+		style = style.Foreground(color)
+	}
+	if m.contextArchived(context) {
+		style = style.Faint(true)
+	}
+	return style
+}
+
+// Context hierarchy. A "/" in a context name nests it under its parent, so
+// "Work/ClientA" is a child of "Work". Parents always exist as contexts of
+// their own; a parent can aggregate its descendants' tasks.
+
+const contextSeparator = "/"
+
+// contextParent returns the parent of context, or "" for a top-level one.
+func contextParent(context string) string {
+	if i := strings.LastIndex(context, contextSeparator); i != -1 {
+		return context[:i]
+	}
+	return ""
+}
+
+// contextLeaf returns the last segment of context.
+func contextLeaf(context string) string {
+	return context[strings.LastIndex(context, contextSeparator)+1:]
+}
+
+func contextDepth(context string) int {
+	return strings.Count(context, contextSeparator)
+}
+
+// isSubContext reports whether context is a descendant of parent.
+func isSubContext(context, parent string) bool {
+	return strings.HasPrefix(context, parent+contextSeparator)
+}
+
+// cleanContextName trims each segment of name and drops empty ones.
+func cleanContextName(name string) string {
+	var segments []string
+	for _, segment := range strings.Split(name, contextSeparator) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, contextSeparator)
+}
+
+// withAncestors returns contexts with every missing ancestor inserted before
+// its first descendant.
+func withAncestors(contexts []string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(contexts))
+	var add func(context string)
+	add = func(context string) {
+		if seen[context] {
+			return
+		}
+		if parent := contextParent(context); parent != "" {
+			add(parent)
+		}
+		seen[context] = true
+		out = append(out, context)
+	}
+	for _, context := range contexts {
+		add(context)
+	}
+	return out
+}
+
+// subContexts returns the descendants of context.
+func (m *Model) subContexts(context string) []string {
+	var subs []string
+	for _, c := range m.Contexts {
+		if isSubContext(c, context) {
+			subs = append(subs, c)
+		}
+	}
+	return subs
+}
+
+// contextArchived reports whether context or one of its ancestors is
+// archived.
+func (m *Model) contextArchived(context string) bool {
+	for ; context != ""; context = contextParent(context) {
+		if m.ContextMeta[context].Archived {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregatedIntoParent reports whether an ancestor of context shows its
+// tasks, in which case the kanban board folds it into that ancestor's column.
+func (m *Model) aggregatedIntoParent(context string) bool {
+	for parent := contextParent(context); parent != ""; parent = contextParent(parent) {
+		if m.ContextMeta[parent].Aggregate {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleAggregateCurrentContext makes the current context show the tasks of
+// its sub-contexts, or stop doing so.
+func (m *Model) ToggleAggregateCurrentContext() {
+	if len(m.subContexts(m.CurrentContext)) == 0 {
+		m.ErrorMessage = fmt.Sprintf("'%s' has no sub-contexts", m.CurrentContext)
+		return
+	}
+	meta := m.ContextMeta[m.CurrentContext]
+	meta.Aggregate = !meta.Aggregate
+	m.setContextMeta(m.CurrentContext, meta)
+	m.SelectedIndex = 0
+}
+
+// treeOrder arranges contexts depth first, each parent followed by its
+// children in their existing order. Children of contexts for which expanded
+// returns false are left out.
+func treeOrder(contexts []string, expanded func(string) bool) []string {
+	rows := make([]string, 0, len(contexts))
+	var walk func(parent string)
+	walk = func(parent string) {
+		for _, context := range contexts {
+			if contextParent(context) == parent {
+				rows = append(rows, context)
+				if expanded(context) {
+					walk(context)
+				}
+			}
+		}
+	}
+	walk("")
+	return rows
+}
+
+// contextTreeRows lists the rows of the context picker.
+func (m *Model) contextTreeRows() []string {
+	return treeOrder(m.VisibleContexts(), func(context string) bool {
+		return !m.TreeCollapsed[context]
+	})
+}
+
+// ShowContextTree opens the context picker on the current context.
+func (m *Model) ShowContextTree() {
+	for parent := contextParent(m.CurrentContext); parent != ""; parent = contextParent(parent) {
+		delete(m.TreeCollapsed, parent)
+	}
+	m.ReturnView = m.ViewMode
+	m.ViewMode = ContextTreeView
+	m.TreeIndex = max(0, slices.Index(m.contextTreeRows(), m.CurrentContext))
+}
+
+// SetTreeCollapsed collapses or expands the context under the picker cursor.
+func (m *Model) SetTreeCollapsed(collapsed bool) {
+	rows := m.contextTreeRows()
+	if m.TreeIndex >= len(rows) {
+		return
+	}
+	if m.TreeCollapsed == nil {
+		m.TreeCollapsed = make(map[string]bool)
+	}
+	if collapsed {
+		m.TreeCollapsed[rows[m.TreeIndex]] = true
+	} else {
+		delete(m.TreeCollapsed, rows[m.TreeIndex])
+	}
+}
@@ -0,0 +1,223 @@
+package todo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSV for spreadsheets. The header row names the Task fields; tags share one
+// column, joined with Settings.CSVTagSeparator. On import, columns are matched
+// to fields by name, or through ImportOptions.Columns for sheets prepared
+// elsewhere, and columns that match no field are ignored.
+
+// csvFields are the columns written on export, in order.
+var csvFields = []string{"id", "task", "checked", "context", "priority", "tags", "due_date", "status", "notes", "uid"}
+
+// csvAliases are common headings for fields under another name.
+var csvAliases = map[string]string{"title": "task", "done": "checked", "due": "due_date", "list": "context"}
+
+// csvTagSeparator returns sep, or the default separator if it is empty.
+func csvTagSeparator(sep string) string {
+	if sep == "" {
+		return DefaultSettings().CSVTagSeparator
+	}
+	return sep
+}
+
+// WriteCSV writes every task as a CSV row below a header row.
+func (m *Model) WriteCSV(w io.Writer) error {
+	sep := csvTagSeparator(m.Settings.CSVTagSeparator)
+	cw := csv.NewWriter(w)
+	cw.Write(csvFields)
+	for _, task := range m.Tasks {
+		cw.Write([]string{
+			strconv.Itoa(task.ID),
+			task.Task,
+			strconv.FormatBool(task.Checked),
+			task.Context,
+			task.Priority,
+			strings.Join(task.Tags, sep),
+			task.DueDate,
+			task.Status,
+			task.Notes,
+			task.UID,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvFieldName turns a column heading such as "Due Date" into a field name.
+func csvFieldName(heading string) string {
+	name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(heading)), " ", "_")
+	if field, ok := csvAliases[name]; ok {
+		return field
+	}
+	return name
+}
+
+// csvColumns maps each field to its column in header, applying the column
+// mapping first.
+func csvColumns(header []string, mapping map[string]string) (map[string]int, []string, error) {
+	columns := make(map[string]int)
+	var ignored []string
+	mapped := make(map[string]bool)
+	for i, heading := range header {
+		field := csvFieldName(heading)
+		for from, to := range mapping {
+			if strings.EqualFold(strings.TrimSpace(from), strings.TrimSpace(heading)) {
+				field = csvFieldName(to)
+				mapped[from] = true
+			}
+		}
+		if !slices.Contains(csvFields, field) {
+			ignored = append(ignored, heading)
+			continue
+		}
+		if _, ok := columns[field]; ok {
+			return nil, nil, fmt.Errorf("more than one column for %s", field)
+		}
+		columns[field] = i
+	}
+
+	for from, to := range mapping {
+		if !slices.Contains(csvFields, csvFieldName(to)) {
+			return nil, nil, fmt.Errorf("cannot map %q to %q: fields are %s", from, to, strings.Join(csvFields, ", "))
+		}
+		if !mapped[from] {
+			return nil, nil, fmt.Errorf("no column %q in the header", from)
+		}
+	}
+	if _, ok := columns["task"]; !ok {
+		return nil, nil, errors.New("no task column; map one to task")
+	}
+	return columns, ignored, nil
+}
+
+// csvChecked reads a done column, accepting the usual spreadsheet spellings.
+func csvChecked(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "false", "no", "n", "0":
+		return false, nil
+	case "true", "yes", "y", "1", "x", "done":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid checked value %q", value)
+}
+
+// parseCSVRow reads a task from a row, reporting every invalid field.
+func parseCSVRow(row []string, columns map[string]int, sep string) (Task, error) {
+	get := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var errs []error
+	task := Task{
+		Task:    get("task"),
+		Context: cleanContextName(get("context")),
+		Status:  get("status"),
+		Notes:   get("notes"),
+		UID:     get("uid"),
+	}
+	if task.Task == "" {
+		errs = append(errs, errors.New("no task text"))
+	}
+	checked, err := csvChecked(get("checked"))
+	errs = append(errs, err)
+	task.Checked = checked
+	if priority := strings.ToLower(get("priority")); slices.Contains([]string{"", "low", "medium", "high"}, priority) {
+		task.Priority = priority
+	} else {
+		errs = append(errs, fmt.Errorf("invalid priority %q: use low, medium or high", get("priority")))
+	}
+	if due := get("due_date"); due != "" {
+		if _, err := time.Parse(time.DateOnly, due); err != nil {
+			errs = append(errs, fmt.Errorf("invalid due date %q: use YYYY-MM-DD", due))
+		}
+		task.DueDate = due
+	}
+	for _, tag := range strings.Split(get("tags"), sep) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Task{}, errors.New(strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	return task, nil
+}
+
+// ImportCSV reads tasks from CSV with a header row. Rows with invalid fields
+// are skipped and reported by line.
+func ImportCSV(r io.Reader, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+	sep := csvTagSeparator(opts.TagSeparator)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // byte order mark from Excel
+	}
+	columns, ignored, err := csvColumns(header, opts.Columns)
+	if err != nil {
+		return result, err
+	}
+	for _, heading := range ignored {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("column %q: not a task field, ignored", heading))
+	}
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: %v", parseErr.StartLine, parseErr.Err))
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		if !slices.ContainsFunc(row, func(field string) bool { return strings.TrimSpace(field) != "" }) {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		task, err := parseCSVRow(row, columns, sep)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	return result, nil
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "csv",
+		Extensions:  []string{".csv"},
+		Description: "comma-separated values for spreadsheets",
+		Export: func(w io.Writer, m *Model) error {
+			return m.WriteCSV(w)
+		},
+		Import: ImportCSV,
+	})
+}
@@ -0,0 +1,94 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    ImportOptions
+		want    []Task
+		skipped []string // prefixes of the skipped entries
+		wantErr string
+	}{
+		{
+			name:  "aliases and spreadsheet booleans",
+			input: "\ufeffTitle,Done,List,Due,Tags,Extra\nBuy milk,yes,Home,2026-10-20,errand;quick,x\nCall,,Work,,,\n",
+			want: []Task{
+				{Task: "Buy milk", Checked: true, Context: "Home", DueDate: "2026-10-20", Tags: []string{"errand", "quick"}},
+				{Task: "Call", Context: "Work"},
+			},
+			skipped: []string{`column "Extra"`},
+		},
+		{
+			name:  "column mapping and tag separator",
+			input: "Summary,Labels,Prio\nWrite report,a|b,HIGH\n",
+			opts:  ImportOptions{Columns: map[string]string{"Summary": "task", "labels": "tags", "Prio": "priority"}, TagSeparator: "|"},
+			want:  []Task{{Task: "Write report", Tags: []string{"a", "b"}, Priority: "high"}},
+		},
+		{
+			name:  "invalid rows are reported by line",
+			input: "task,priority,due_date,checked\nGood,low,,\n\"Multi\nline\",urgent,2026-02-30,maybe\n,,,\nAlso good,,,\n",
+			want:  []Task{{Task: "Good", Priority: "low"}, {Task: "Also good"}},
+			skipped: []string{
+				`line 3: invalid checked value "maybe"; invalid priority "urgent": use low, medium or high; invalid due date "2026-02-30"`,
+			},
+		},
+		{name: "no task column", input: "name,done\nx,y\n", wantErr: "no task column"},
+		{name: "duplicate column", input: "task,title\na,b\n", wantErr: "more than one column for task"},
+		{name: "mapping to an unknown field", input: "a\nb\n", opts: ImportOptions{Columns: map[string]string{"a": "size"}}, wantErr: `cannot map "a" to "size"`},
+		{name: "mapping a missing column", input: "task\nb\n", opts: ImportOptions{Columns: map[string]string{"Name": "task"}}, wantErr: `no column "Name"`},
+		{name: "empty input", input: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ImportCSV(strings.NewReader(tt.input), tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ImportCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Tasks) != len(tt.want) {
+				t.Fatalf("imported %+v, want %+v", result.Tasks, tt.want)
+			}
+			for i := range tt.want {
+				if !tasksEqual(result.Tasks[i], tt.want[i]) {
+					t.Errorf("task %d\n got %+v\nwant %+v", i, result.Tasks[i], tt.want[i])
+				}
+			}
+			if len(result.Skipped) != len(tt.skipped) {
+				t.Fatalf("skipped %q, want %q", result.Skipped, tt.skipped)
+			}
+			for i, prefix := range tt.skipped {
+				if !strings.HasPrefix(result.Skipped[i], prefix) {
+					t.Errorf("skipped[%d] = %q, want prefix %q", i, result.Skipped[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVRoundTripWithCustomSeparator(t *testing.T) {
+	m := testModel()
+	m.Settings.CSVTagSeparator = "|"
+	m.Tasks[0].Tags = []string{"a;b", "c"}
+	var buf bytes.Buffer
+	if err := m.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportCSV(&buf, ImportOptions{TagSeparator: "|"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Tasks[0].Tags; len(got) != 2 || got[0] != "a;b" || got[1] != "c" {
+		t.Errorf("tags = %q", got)
+	}
+}
@@ -0,0 +1,221 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Note file checks. The loader quietly copes with most inconsistencies, but
+// some, such as duplicate IDs, make edits land on the wrong task. Diagnosing
+// a file describes each problem and how it would be repaired; repairing
+// applies those fixes after backing the file up.
+
+// Problem is an inconsistency in a note file.
+type Problem struct {
+	Location string // where in the file, e.g. "tasks[3] (id 7)"
+	Message  string
+	Fix      string // what repairing does about it, empty if it can't
+}
+
+func (p Problem) String() string {
+	s := p.Location + ": " + p.Message
+	if p.Fix != "" {
+		s += " (fix: " + p.Fix + ")"
+	}
+	return s
+}
+
+// checkNoteFile fixes the problems of file, read from path, in place and
+// describes them.
+func checkNoteFile(path string, file *noteFile) []Problem {
+	var problems []Problem
+	report := func(location, fix, format string, args ...any) {
+		problems = append(problems, Problem{Location: location, Message: fmt.Sprintf(format, args...), Fix: fix})
+	}
+
+	maxID := 0
+	for _, task := range file.Tasks {
+		maxID = max(maxID, task.ID)
+	}
+	if file.NextID <= maxID {
+		report("next_id", fmt.Sprintf("set to %d", maxID+1), "next_id %d is not above the highest id %d", file.NextID, maxID)
+		file.NextID = maxID + 1
+	}
+
+	if len(file.Statuses) > 0 {
+		if err := validateStatuses(file.Statuses); err != nil {
+			repaired := repairStatuses(file.Statuses)
+			report("statuses", "set to "+strings.Join(repaired, ", "), "%v", err)
+			file.Statuses = repaired
+		}
+	}
+
+	seen := make(map[string]bool)
+	contexts := file.Contexts[:0]
+	for i, context := range file.Contexts {
+		if seen[context] {
+			report(fmt.Sprintf("contexts[%d]", i), "removed", "%q is listed twice", context)
+			continue
+		}
+		seen[context] = true
+		contexts = append(contexts, context)
+	}
+	file.Contexts = contexts
+
+	ids := make(map[int]int)     // id to index of its first task
+	uids := make(map[string]int) // likewise for UIDs
+	for i := range file.Tasks {
+		task := &file.Tasks[i]
+		location := fmt.Sprintf("tasks[%d] (id %d)", i, task.ID)
+
+		if first, ok := ids[task.ID]; ok || task.ID <= 0 {
+			fix := fmt.Sprintf("renumbered to %d", file.NextID)
+			if ok {
+				report(location, fix, "duplicate id, also used by tasks[%d]", first)
+			} else {
+				report(location, fix, "invalid id %d", task.ID)
+			}
+			task.ID = file.NextID
+			file.NextID++
+		} else {
+			ids[task.ID] = i
+		}
+
+		switch first, ok := uids[task.UID]; {
+		case task.UID == "":
+			// The UID loading gives it in memory, so exports made before
+			// the repair keep matching.
+			report(location, "given a uid", "no uid")
+			task.UID = legacyTaskUID(path, task.ID)
+		case ok:
+			report(location, "given a new uid", "duplicate uid %q, also used by tasks[%d]", task.UID, first)
+			task.UID = newTaskUID()
+		default:
+			uids[task.UID] = i
+		}
+
+		if strings.TrimSpace(task.Task) == "" {
+			report(location, `set to "(untitled)"`, "empty task text")
+			task.Task = "(untitled)"
+		}
+
+		if !slices.Contains([]string{"", "low", "medium", "high"}, task.Priority) {
+			normalised := strings.ToLower(strings.TrimSpace(task.Priority))
+			if slices.Contains([]string{"low", "medium", "high"}, normalised) {
+				report(location, "set to "+normalised, "priority %q is not low, medium or high", task.Priority)
+				task.Priority = normalised
+			} else {
+				report(location, "cleared", "priority %q is not low, medium or high", task.Priority)
+				task.Priority = ""
+			}
+		}
+
+		if task.DueDate != "" {
+			if _, err := time.Parse(time.DateOnly, task.DueDate); err != nil {
+				due := strings.TrimSpace(task.DueDate)
+				if _, err := time.Parse(time.DateOnly, due); err == nil {
+					report(location, "set to "+due, "due date %q has surrounding spaces", task.DueDate)
+					task.DueDate = due
+				} else {
+					report(location, "moved to the notes", "due date %q is not a YYYY-MM-DD date", task.DueDate)
+					if task.Notes != "" {
+						task.Notes += "\n"
+					}
+					task.Notes += "Due: " + task.DueDate
+					task.DueDate = ""
+				}
+			}
+		}
+
+		switch {
+		case task.Context == "":
+			context := "Inbox"
+			if len(file.Contexts) > 0 {
+				context = file.Contexts[0]
+			}
+			report(location, fmt.Sprintf("moved to %q", context), "task has no context")
+			task.Context = context
+		case !slices.Contains(file.Contexts, task.Context):
+			report(location, "added to contexts", "context %q is not in contexts", task.Context)
+		}
+		if !slices.Contains(file.Contexts, task.Context) {
+			file.Contexts = append(file.Contexts, task.Context)
+		}
+	}
+	return problems
+}
+
+// readNoteFile decodes the note file at path. A file that isn't valid JSON
+// is reported as a problem rather than an error.
+func readNoteFile(path string) (noteFile, []byte, []Problem, error) {
+	var file noteFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, nil, nil, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		location := path
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			location = jsonPosition(data, syntaxErr.Offset)
+		case errors.As(err, &typeErr):
+			location = jsonPosition(data, typeErr.Offset)
+		}
+		return file, data, []Problem{{Location: location, Message: "not a valid note file: " + err.Error()}}, nil
+	}
+	return file, data, nil, nil
+}
+
+// jsonPosition turns the offset of a JSON error, which is just past the
+// offending byte, into that byte's line and column.
+func jsonPosition(data []byte, offset int64) string {
+	before := data[:max(0, min(int(offset), len(data))-1)]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// DiagnoseNoteFile reports the problems of the note file at path without
+// changing it.
+func DiagnoseNoteFile(path string) ([]Problem, error) {
+	file, _, problems, err := readNoteFile(path)
+	if err != nil || problems != nil {
+		return problems, err
+	}
+	return checkNoteFile(path, &file), nil
+}
+
+// RepairNoteFile fixes the problems of the note file at path, first copying
+// it to a backup next to it. It returns the problems found and the backup's
+// path, which is empty if there was nothing to fix.
+func RepairNoteFile(path string) ([]Problem, string, error) {
+	file, data, problems, err := readNoteFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if problems != nil {
+		return problems, "", errors.New("the file can't be read, so it has to be fixed by hand")
+	}
+	problems = checkNoteFile(path, &file)
+	if len(problems) == 0 {
+		return nil, "", nil
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return problems, "", fmt.Errorf("backing up: %w", err)
+	}
+	fixed, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return problems, backup, err
+	}
+	return problems, backup, os.WriteFile(path, fixed, 0644)
+}
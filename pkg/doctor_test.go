@@ -0,0 +1,164 @@
+package todo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeNoteFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "note.json")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiagnoseNoteFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string // problems, as "location: message (fix: ...)"
+	}{
+		{
+			name: "clean",
+			data: `{"tasks": [{"id": 1, "task": "A", "context": "Work", "priority": "low", "due_date": "2026-10-20", "uid": "a"}], "next_id": 2, "contexts": ["Work"]}`,
+		},
+		{
+			name: "ids",
+			data: `{"tasks": [{"id": 1, "task": "A", "context": "Work", "uid": "a"}, {"id": 1, "task": "B", "context": "Work", "uid": "b"}, {"id": 0, "task": "C", "context": "Work", "uid": "c"}], "next_id": 1, "contexts": ["Work"]}`,
+			want: []string{
+				"next_id: next_id 1 is not above the highest id 1 (fix: set to 2)",
+				"tasks[1] (id 1): duplicate id, also used by tasks[0] (fix: renumbered to 2)",
+				"tasks[2] (id 0): invalid id 0 (fix: renumbered to 3)",
+			},
+		},
+		{
+			name: "fields",
+			data: `{"tasks": [
+				{"id": 1, "task": " ", "context": "Work", "priority": "High", "uid": "u"},
+				{"id": 2, "task": "B", "context": "Home", "priority": "urgent", "due_date": " 2026-10-20", "uid": "u"},
+				{"id": 3, "task": "C", "due_date": "tomorrow"}
+			], "next_id": 4, "contexts": ["Work", "Work"], "statuses": ["Todo"]}`,
+			want: []string{
+				"statuses: ",
+				"contexts[1]: \"Work\" is listed twice (fix: removed)",
+				`tasks[0] (id 1): empty task text (fix: set to "(untitled)")`,
+				`tasks[0] (id 1): priority "High" is not low, medium or high (fix: set to high)`,
+				`tasks[1] (id 2): duplicate uid "u", also used by tasks[0] (fix: given a new uid)`,
+				`tasks[1] (id 2): priority "urgent" is not low, medium or high (fix: cleared)`,
+				`tasks[1] (id 2): due date " 2026-10-20" has surrounding spaces (fix: set to 2026-10-20)`,
+				`tasks[1] (id 2): context "Home" is not in contexts (fix: added to contexts)`,
+				`tasks[2] (id 3): no uid (fix: given a uid)`,
+				`tasks[2] (id 3): due date "tomorrow" is not a YYYY-MM-DD date (fix: moved to the notes)`,
+				`tasks[2] (id 3): task has no context (fix: moved to "Work")`,
+			},
+		},
+		{
+			name: "invalid JSON",
+			data: "{\"tasks\": [\n  {\"id\": 1,}\n]}",
+			want: []string{"line 2, column 12: not a valid note file: "},
+		},
+		{
+			name: "wrong type",
+			data: `{"tasks": [{"id": "one"}]}`,
+			want: []string{"line 1, column 23: not a valid note file: "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := DiagnoseNoteFile(writeNoteFile(t, tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problems %q, want %q", problems, tt.want)
+			}
+			for i, want := range tt.want {
+				if got := problems[i].String(); !strings.HasPrefix(got, want) {
+					t.Errorf("problem %d = %q, want prefix %q", i, got, want)
+				}
+			}
+		})
+	}
+
+	if _, err := DiagnoseNoteFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("diagnosing a missing file did not fail")
+	}
+}
+
+func TestRepairNoteFile(t *testing.T) {
+	data := `{"tasks": [{"id": 1, "task": "A", "context": "Work", "uid": "a"}, {"id": 1, "task": "B", "priority": "High", "due_date": "soon", "uid": "b"}], "next_id": 1, "contexts": ["Work"]}`
+	path := writeNoteFile(t, data)
+
+	problems, backup, err := RepairNoteFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 5 {
+		t.Errorf("problems %q", problems)
+	}
+	if got, _ := os.ReadFile(backup); string(got) != data {
+		t.Errorf("backup %s holds %q", backup, got)
+	}
+	if filepath.Dir(backup) != filepath.Dir(path) || !strings.HasSuffix(backup, ".bak") {
+		t.Errorf("backup path %q", backup)
+	}
+
+	problems, err = DiagnoseNoteFile(path)
+	if err != nil || len(problems) != 0 {
+		t.Fatalf("after repair: %q, %v", problems, err)
+	}
+	m := Model{ConfigFilePath: path, Settings: DefaultSettings()}
+	m.LoadConfig()
+	if len(m.Tasks) != 2 || m.Tasks[1].ID != 2 || m.Tasks[1].Priority != "high" || m.Tasks[1].Notes != "Due: soon" || m.NextID != 3 {
+		t.Errorf("repaired tasks %+v, next id %d", m.Tasks, m.NextID)
+	}
+
+	problems, backup, err = RepairNoteFile(path)
+	if err != nil || len(problems) != 0 || backup != "" {
+		t.Errorf("repairing a clean file: %q, %q, %v", problems, backup, err)
+	}
+}
+
+func TestRepairNoteFileKeepsLegacyUIDs(t *testing.T) {
+	path := writeNoteFile(t, `{"tasks": [{"id": 1, "task": "A", "context": "Work"}, {"id": 2, "task": "B", "context": "Work"}], "next_id": 3, "contexts": ["Work"]}`)
+	uids := func() []string {
+		m := Model{ConfigFilePath: path, Settings: DefaultSettings()}
+		m.LoadConfig()
+		return []string{m.Tasks[0].UID, m.Tasks[1].UID}
+	}
+	loaded := uids()
+
+	problems, _, err := RepairNoteFile(path)
+	if err != nil || len(problems) != 2 || problems[0].Message != "no uid" {
+		t.Fatalf("RepairNoteFile() = %q, %v", problems, err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, uid := range loaded {
+		if !strings.Contains(string(data), `"uid": "`+uid+`"`) {
+			t.Errorf("repaired file lacks uid %s:\n%s", uid, data)
+		}
+	}
+	if repaired := uids(); repaired[0] != loaded[0] || repaired[1] != loaded[1] {
+		t.Errorf("UIDs changed from %q to %q", loaded, repaired)
+	}
+}
+
+func TestRepairNoteFileRefusesInvalidJSON(t *testing.T) {
+	data := `{"tasks": [`
+	path := writeNoteFile(t, data)
+	problems, backup, err := RepairNoteFile(path)
+	if err == nil || len(problems) != 1 || backup != "" {
+		t.Errorf("RepairNoteFile() = %q, %q, %v", problems, backup, err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, []byte(data)) {
+		t.Errorf("file changed to %q", got)
+	}
+	if matches, _ := filepath.Glob(path + ".*.bak"); len(matches) != 0 {
+		t.Errorf("backups %q", matches)
+	}
+}
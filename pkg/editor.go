@@ -0,0 +1,247 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// External editor support. A task is written as a small front-matter
+// document, with the notes as the body:
+//
+//	---
+//	title: Write report
+//	context: Work
+//	status: In Progress
+//	priority: high
+//	tags: writing, q3
+//	due: 2026-10-20
+//	---
+//	Free-form notes.
+
+const frontMatterDelim = "---"
+
+// editorFinishedMsg is sent when the editor launched by OpenInEditor exits.
+type editorFinishedMsg struct {
+	taskID int
+	path   string
+	err    error
+}
+
+// EditorCommand builds the command that opens path in $VISUAL or $EDITOR,
+// falling back to vi.
+func EditorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// FormatTaskDocument renders a task in the editor file format.
+func FormatTaskDocument(task Task) string {
+	var b strings.Builder
+	b.WriteString(frontMatterDelim + "\n")
+	fmt.Fprintf(&b, "title: %s\n", task.Task)
+	fmt.Fprintf(&b, "context: %s\n", task.Context)
+	fmt.Fprintf(&b, "status: %s\n", task.Status)
+	fmt.Fprintf(&b, "priority: %s\n", task.Priority)
+	fmt.Fprintf(&b, "tags: %s\n", strings.Join(task.Tags, ", "))
+	fmt.Fprintf(&b, "due: %s\n", task.DueDate)
+	b.WriteString(frontMatterDelim + "\n")
+	if task.Notes != "" {
+		b.WriteString(task.Notes + "\n")
+	}
+	return b.String()
+}
+
+// ParseTaskDocument reads an editor document back onto a copy of task,
+// validating every field against the model's workflow.
+func (m *Model) ParseTaskDocument(task Task, doc string) (Task, error) {
+	scanner := bufio.NewScanner(strings.NewReader(doc))
+	line := 0
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		line++
+		return scanner.Text(), true
+	}
+
+	if first, ok := next(); !ok || strings.TrimSpace(first) != frontMatterDelim {
+		return task, fmt.Errorf("line 1: expected %q to open the front matter", frontMatterDelim)
+	}
+
+	seen := make(map[string]bool)
+	closed := false
+	for {
+		text, ok := next()
+		if !ok {
+			break
+		}
+		if strings.TrimSpace(text) == frontMatterDelim {
+			closed = true
+			break
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(text, ":")
+		if !found {
+			return task, fmt.Errorf("line %d: expected \"key: value\"", line)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if seen[name] {
+			return task, fmt.Errorf("line %d: duplicate field %q", line, name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "title":
+			if value == "" {
+				return task, fmt.Errorf("line %d: title cannot be empty", line)
+			}
+			task.Task = value
+		case "context":
+			if value == "" {
+				return task, fmt.Errorf("line %d: context cannot be empty", line)
+			}
+			task.Context = value
+		case "status":
+			if value != "" && !slices.Contains(m.Statuses, value) {
+				return task, fmt.Errorf("line %d: unknown status %q (want one of %s)", line, value, strings.Join(m.Statuses, ", "))
+			}
+			task.Status = value
+		case "priority":
+			value = strings.ToLower(value)
+			if !slices.Contains([]string{"", "low", "medium", "high"}, value) {
+				return task, fmt.Errorf("line %d: invalid priority %q (want low, medium or high)", line, value)
+			}
+			task.Priority = value
+		case "tags":
+			task.Tags = nil
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(task.Tags, tag) {
+					task.Tags = append(task.Tags, tag)
+				}
+			}
+		case "due":
+			if value != "" {
+				if _, err := time.Parse(time.DateOnly, value); err != nil {
+					return task, fmt.Errorf("line %d: invalid due date %q, use YYYY-MM-DD", line, value)
+				}
+			}
+			task.DueDate = value
+		default:
+			return task, fmt.Errorf("line %d: unknown field %q", line, name)
+		}
+	}
+	if !closed {
+		return task, fmt.Errorf("line %d: front matter is not closed with %q", line, frontMatterDelim)
+	}
+
+	var notes []string
+	for {
+		text, ok := next()
+		if !ok {
+			break
+		}
+		notes = append(notes, text)
+	}
+	task.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+
+	if task.Status == "" {
+		task.Status = m.Statuses[0]
+	}
+	task.Checked = task.Status == m.Statuses[len(m.Statuses)-1]
+	return task, nil
+}
+
+// WriteTaskFile writes the task with the given ID to a temporary file for
+// editing and returns its path.
+func (m *Model) WriteTaskFile(id int) (string, error) {
+	idx := m.findTaskIndexByID(id)
+	if idx == -1 {
+		return "", fmt.Errorf("no task with ID %d", id)
+	}
+	f, err := os.CreateTemp("", fmt.Sprintf("srn-todo-%d-*.md", id))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(FormatTaskDocument(m.Tasks[idx])); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// ApplyTaskFile reads an edited task file back into the task with the given
+// ID. The task is left untouched if the file doesn't validate.
+func (m *Model) ApplyTaskFile(id int, path string) error {
+	idx := m.findTaskIndexByID(id)
+	if idx == -1 {
+		return fmt.Errorf("no task with ID %d", id)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	task, err := m.ParseTaskDocument(m.Tasks[idx], string(data))
+	if err != nil {
+		return err
+	}
+	m.Tasks[idx] = task
+	m.UpdateContexts()
+	return nil
+}
+
+// OpenInEditor suspends the TUI and edits the selected task in an external
+// editor.
+func (m *Model) OpenInEditor() tea.Cmd {
+	task := m.GetCurrentTask()
+	if task.ID == 0 {
+		return nil
+	}
+	path, err := m.WriteTaskFile(task.ID)
+	if err != nil {
+		m.ErrorMessage = fmt.Sprintf("Cannot open editor: %v", err)
+		return nil
+	}
+	return tea.ExecProcess(EditorCommand(path), func(err error) tea.Msg {
+		return editorFinishedMsg{taskID: task.ID, path: path, err: err}
+	})
+}
+
+// finishEditing applies the result of an OpenInEditor session. A file that
+// doesn't validate is kept so the edits aren't lost.
+func (m *Model) finishEditing(msg editorFinishedMsg) {
+	if msg.err != nil {
+		os.Remove(msg.path)
+		m.ErrorMessage = fmt.Sprintf("Editor failed: %v", msg.err)
+		return
+	}
+	m.SaveStateForUndo()
+	if err := m.ApplyTaskFile(msg.taskID, msg.path); err != nil {
+		m.History = m.History[:len(m.History)-1]
+		m.ErrorMessage = fmt.Sprintf("Edit not applied: %v; your text is kept in %s", err, msg.path)
+		return
+	}
+	os.Remove(msg.path)
+	m.SaveConfig()
+	m.SelectTask(msg.taskID)
+	if m.ViewMode == KanbanView {
+		m.refocusKanban()
+	}
+}
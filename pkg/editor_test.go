@@ -0,0 +1,56 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFinishEditingKeepsInvalidFile(t *testing.T) {
+	home := isolateHome(t)
+	m := Model{
+		ConfigFilePath: filepath.Join(home, "note.json"),
+		Statuses:       slices.Clone(DefaultStatuses),
+		Tasks:          []Task{{ID: 1, Task: "Write report", Context: "Work", Status: "Todo"}},
+		Contexts:       []string{"Work"},
+		CurrentContext: "Work",
+		MaxHistory:     10,
+	}
+
+	path, err := m.WriteTaskFile(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(FormatTaskDocument(m.Tasks[0]), "priority: ", "priority: urgent", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.finishEditing(editorFinishedMsg{taskID: 1, path: path})
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("invalid edit file was removed: %v", err)
+	}
+	if !strings.Contains(m.ErrorMessage, path) {
+		t.Errorf("ErrorMessage %q does not mention %s", m.ErrorMessage, path)
+	}
+	if len(m.History) != 0 {
+		t.Errorf("undo history has %d entries after a rejected edit", len(m.History))
+	}
+
+	edited = strings.Replace(FormatTaskDocument(m.Tasks[0]), "priority: ", "priority: high", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.ErrorMessage = ""
+	m.finishEditing(editorFinishedMsg{taskID: 1, path: path})
+	if m.ErrorMessage != "" {
+		t.Fatal(m.ErrorMessage)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("edit file still exists after a successful edit")
+	}
+	if m.Tasks[0].Priority != "high" {
+		t.Errorf("priority = %q, want high", m.Tasks[0].Priority)
+	}
+}
@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// maxRecentFiles caps the recently opened list shown by the file switcher.
+const maxRecentFiles = 10
+
+// StateDir returns the srn-todo directory under $XDG_STATE_HOME, falling
+// back to ~/.local/state.
+func StateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" || !filepath.IsAbs(dir) {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(dir, "srn-todo")
+}
+
+// RecentFilesPath returns the file listing recently opened note files.
+func RecentFilesPath() string {
+	return filepath.Join(StateDir(), "recent.json")
+}
+
+// LoadRecentFiles returns the recently opened note files, newest first.
+func LoadRecentFiles() []string {
+	var recent []string
+	data, err := os.ReadFile(RecentFilesPath())
+	if err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return nil
+	}
+	return recent
+}
+
+// recordRecentFile moves path to the front of the recent files list.
+func recordRecentFile(path string) {
+	recent := slices.DeleteFunc(LoadRecentFiles(), func(p string) bool {
+		return p == path
+	})
+	recent = append([]string{path}, recent...)
+	if len(recent) > maxRecentFiles {
+		recent = recent[:maxRecentFiles]
+	}
+	data, err := json.MarshalIndent(recent, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(StateDir(), 0755); err != nil {
+		return
+	}
+	os.WriteFile(RecentFilesPath(), data, 0644)
+}
+
+// OpenNoteFile loads the note file at path into the model, replacing all
+// task state.
+func (m *Model) OpenNoteFile(path string) {
+	m.ConfigFilePath = path
+	m.Tasks = nil
+	m.Contexts = nil
+	m.ContextMeta = nil
+	m.Statuses = nil
+	m.History = nil
+	m.CurrentContext = ""
+	m.SelectedIndex = 0
+	m.NormalScrollY = 0
+	m.MovingMode = false
+	m.KanbanCol, m.KanbanRow = 0, 0
+	m.KanbanScrollX, m.KanbanScrollY = 0, 0
+
+	m.LoadConfig()
+	if ctx := m.Settings.DefaultContext; ctx != "" && !slices.Contains(m.Contexts, ctx) {
+		m.Contexts = append(m.Contexts, ctx)
+	}
+	m.UpdateContexts()
+	if ctx := m.Settings.DefaultContext; ctx != "" {
+		m.CurrentContext = ctx
+	}
+	recordRecentFile(path)
+}
+
+// FileSwitcherEntries lists the configured lists followed by recently opened
+// files, without duplicates.
+func (m *Model) FileSwitcherEntries() []string {
+	var entries []string
+	for _, path := range m.Settings.Lists {
+		if abs, err := filepath.Abs(ExpandHome(path)); err == nil && !slices.Contains(entries, abs) {
+			entries = append(entries, abs)
+		}
+	}
+	for _, path := range LoadRecentFiles() {
+		if !slices.Contains(entries, path) {
+			entries = append(entries, path)
+		}
+	}
+	if !slices.Contains(entries, m.ConfigFilePath) {
+		entries = append([]string{m.ConfigFilePath}, entries...)
+	}
+	return entries
+}
+
+// ShowFileSwitcher opens the list of note files to switch to.
+func (m *Model) ShowFileSwitcher() {
+	m.ReturnView = m.ViewMode
+	m.ViewMode = FileSwitcherView
+	m.FileEntries = m.FileSwitcherEntries()
+	m.FileIndex = max(0, slices.Index(m.FileEntries, m.ConfigFilePath))
+}
+
+// SwitchFile saves the current note file and opens another one.
+func (m *Model) SwitchFile(path string) {
+	if path != m.ConfigFilePath {
+		if m.LoadErr == nil {
+			m.SaveConfigNow()
+		}
+		m.OpenNoteFile(path)
+	}
+	m.ViewMode = NormalView
+	m.ScrollToSelection()
+}
@@ -0,0 +1,185 @@
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Import and export formats. Each format registers itself from an init
+// function; Export writes the whole note file and Import, if supported,
+// returns the tasks it read together with a description of anything it had
+// to skip.
+
+// Format converts a note file to and from another representation.
+type Format struct {
+	Name        string
+	Extensions  []string // file extensions, with the dot, used to guess the format
+	Description string
+	Export      func(w io.Writer, m *Model) error
+	Import      func(r io.Reader, opts ImportOptions) (ImportResult, error)
+}
+
+// ImportOptions adjust how an importer reads its input; formats ignore the
+// options that don't apply to them.
+type ImportOptions struct {
+	TagSeparator string            // separator between tags in a single field
+	Columns      map[string]string // input column name to field name
+}
+
+// ImportResult holds what an importer read.
+type ImportResult struct {
+	Tasks    []Task
+	Contexts []string // contexts in the order they appeared, including empty ones
+	Skipped  []string // problems, one per skipped item, with its location
+}
+
+var formats = map[string]Format{}
+
+func registerFormat(f Format) {
+	formats[f.Name] = f
+}
+
+// FormatNames lists the registered formats alphabetically.
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupFormat finds a format by name or file extension.
+func LookupFormat(name string) (Format, error) {
+	if f, ok := formats[name]; ok {
+		return f, nil
+	}
+	ext := "." + strings.TrimPrefix(name, ".")
+	for _, f := range formats {
+		if slices.Contains(f.Extensions, ext) {
+			return f, nil
+		}
+	}
+	return Format{}, fmt.Errorf("unknown format %q (known: %s)", name, strings.Join(FormatNames(), ", "))
+}
+
+// FormatForPath guesses the format of path from its extension.
+func FormatForPath(path string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return Format{}, fmt.Errorf("cannot tell the format of %s; pass one explicitly", path)
+	}
+	return LookupFormat(ext)
+}
+
+// ExportFile writes the note file to path in the given format.
+func (m *Model) ExportFile(f Format, path string) error {
+	if f.Export == nil {
+		return fmt.Errorf("%s cannot be exported", f.Name)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.Export(file, m); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ImportCount is how many tasks an import added to and changed in a context.
+type ImportCount struct {
+	Context        string
+	Added, Updated int
+}
+
+// CountChanges counts the tasks added and changed from before to after by
+// context, in the order the contexts first appear in after.
+func CountChanges(before, after []Task) []ImportCount {
+	var counts []ImportCount
+	for _, change := range diffTasks(before, after) {
+		if change.New == nil {
+			continue
+		}
+		i := slices.IndexFunc(counts, func(c ImportCount) bool { return c.Context == change.New.Context })
+		if i == -1 {
+			counts = append(counts, ImportCount{Context: change.New.Context})
+			i = len(counts) - 1
+		}
+		if change.Old == nil {
+			counts[i].Added++
+		} else {
+			counts[i].Updated++
+		}
+	}
+	return counts
+}
+
+// ImportTasks adds imported tasks to the note file with fresh IDs. A task
+// whose UID matches an existing task replaces that task's fields instead.
+// It returns the tasks it added and changed by context; hooks may still
+// refuse some of them when the note file is saved.
+func (m *Model) ImportTasks(result ImportResult) []ImportCount {
+	before := slices.Clone(m.Tasks)
+	m.Contexts = append(m.Contexts, result.Contexts...)
+	for _, task := range result.Tasks {
+		if idx := m.findTaskIndexByUID(task.UID); idx != -1 {
+			old := m.Tasks[idx]
+			task.ID = old.ID
+			if task.Context == "" {
+				task.Context = old.Context
+			}
+			if task.Status == "" && task.Checked == old.Checked {
+				task.Status = old.Status
+			}
+			m.Tasks[idx] = task
+			continue
+		}
+		task.ID = m.NextID
+		m.NextID++
+		if task.Context == "" {
+			task.Context = m.CurrentContext
+		}
+		if task.UID == "" {
+			task.UID = newTaskUID()
+		}
+		m.Tasks = append(m.Tasks, task)
+	}
+	m.normalizeStatuses()
+	m.UpdateContexts()
+	return CountChanges(before, m.Tasks)
+}
+
+// findTaskIndexByUID returns the index of the task with uid, or -1.
+func (m *Model) findTaskIndexByUID(uid string) int {
+	if uid == "" {
+		return -1
+	}
+	return slices.IndexFunc(m.Tasks, func(t Task) bool { return t.UID == uid })
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "json",
+		Extensions:  []string{".json"},
+		Description: "srn-todo note file",
+		Export: func(w io.Writer, m *Model) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(m.noteFile())
+		},
+		Import: func(r io.Reader, _ ImportOptions) (ImportResult, error) {
+			var file noteFile
+			if err := json.NewDecoder(r).Decode(&file); err != nil {
+				return ImportResult{}, err
+			}
+			return ImportResult{Tasks: file.Tasks, Contexts: file.Contexts}, nil
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package todo
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// testModel returns a note file exercising every task field.
+func testModel() *Model {
+	m := &Model{
+		Settings:       DefaultSettings(),
+		Statuses:       slices.Clone(DefaultStatuses),
+		Contexts:       []string{"Work", "Home"},
+		CurrentContext: "Work",
+		NextID:         4,
+		Tasks: []Task{
+			{
+				ID: 1, UID: "7d4c0a4e-1f7e-4a5e-9d1e-3c2b1a0f9e8d", Task: "Ship the release, v2; then rest",
+				Context: "Work", Priority: "high", Tags: []string{"deploy", "q3"}, DueDate: "2026-10-20",
+				Notes: "First line\nsecond line", Status: "In Progress",
+			},
+			{ID: 2, UID: "0b6f6f0e-58a4-4c4e-8d4b-2f8c1c7d5e6a", Task: "Buy milk", Context: "Home", Checked: true},
+			{ID: 3, UID: "c2a8e0b1-9f3d-4e7a-b6c5-d4e3f2a1b0c9", Task: "Call the bank", Context: "Home", Priority: "low"},
+		},
+	}
+	m.normalizeStatuses()
+	m.SavedTasks = slices.Clone(m.Tasks)
+	return m
+}
+
+// roundTrip exports m in format name and imports the result.
+func roundTrip(t *testing.T, m *Model, name string) ImportResult {
+	t.Helper()
+	f, err := LookupFormat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Export(&buf, m); err != nil {
+		t.Fatalf("export %s: %v", name, err)
+	}
+	result, err := f.Import(&buf, ImportOptions{})
+	if err != nil {
+		t.Fatalf("import %s: %v", name, err)
+	}
+	if len(result.Skipped) > 0 {
+		t.Fatalf("import %s skipped %q", name, result.Skipped)
+	}
+	return result
+}
+
+func TestRoundTripIntoEmptyFile(t *testing.T) {
+	for _, name := range FormatNames() {
+		if formats[name].Export == nil || formats[name].Import == nil {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			want := testModel().Tasks
+			empty := &Model{Statuses: slices.Clone(DefaultStatuses), NextID: 1}
+			counts := empty.ImportTasks(roundTrip(t, testModel(), name))
+			wantCounts := []ImportCount{{Context: "Work", Added: 1}, {Context: "Home", Added: 2}}
+			if !slices.Equal(counts, wantCounts) {
+				t.Fatalf("ImportTasks() = %+v, want %+v", counts, wantCounts)
+			}
+			for i, task := range empty.Tasks {
+				if !tasksEqual(task, want[i]) {
+					t.Errorf("imported task %d:\n got %+v\nwant %+v", i, task, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReimportUpdatesByUID(t *testing.T) {
+	for _, name := range FormatNames() {
+		if formats[name].Export == nil || formats[name].Import == nil {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			m := testModel()
+			want := slices.Clone(m.Tasks)
+			result := roundTrip(t, m, name)
+			result.Tasks[1].Priority = "high"
+			want[1].Priority = "high"
+			counts := m.ImportTasks(result)
+			if wantCounts := []ImportCount{{Context: "Home", Updated: 1}}; !slices.Equal(counts, wantCounts) {
+				t.Fatalf("ImportTasks() = %+v, want %+v", counts, wantCounts)
+			}
+			for i, task := range m.Tasks {
+				if !tasksEqual(task, want[i]) {
+					t.Errorf("task %d after re-import:\n got %+v\nwant %+v", task.ID, task, want[i])
+				}
+			}
+		})
+	}
+}
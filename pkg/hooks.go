@@ -0,0 +1,371 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Hooks are executables in the hooks directory that run when a change to a
+// task is saved, from the TUI and from CLI commands alike. They are named
+// after their event, optionally with a suffix ("on-add", "on-add.deploy"),
+// and run in name order. Each gets the task as it was and as it will be on
+// two lines of JSON on stdin, null where there is none, and $TODO_EVENT and
+// $TODO_FILE in its environment. A hook may print a task to save instead,
+// which the next hook receives, or exit non-zero to refuse the change; its
+// first line of output is then shown as the reason.
+//
+// Completing a task runs on-complete rather than on-modify. The TUI runs
+// hooks in the background and writes the file once they finish, so a slow
+// hook doesn't hold up the interface.
+
+// Hook events.
+const (
+	HookAdd      = "on-add"
+	HookModify   = "on-modify"
+	HookComplete = "on-complete"
+	HookDelete   = "on-delete"
+)
+
+// HooksDir returns the directory hooks are run from.
+func HooksDir() string {
+	return filepath.Join(ConfigDir(), "hooks")
+}
+
+// hookScripts lists the executables in the hooks directory by event.
+func hookScripts() map[string][]string {
+	entries, err := os.ReadDir(HooksDir())
+	if err != nil {
+		return nil
+	}
+	scripts := make(map[string][]string)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			continue
+		}
+		for _, event := range []string{HookAdd, HookModify, HookComplete, HookDelete} {
+			if name := entry.Name(); name == event || strings.HasPrefix(name, event+".") {
+				scripts[event] = append(scripts[event], filepath.Join(HooksDir(), name))
+			}
+		}
+	}
+	return scripts
+}
+
+// firstLine returns the first non-empty line of out.
+func firstLine(out []byte) string {
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// hookRunner runs the hooks for changes to one note file. It doesn't touch
+// the model, so the TUI can run it in the background.
+type hookRunner struct {
+	scripts map[string][]string
+	file    string
+	timeout time.Duration
+}
+
+func (m *Model) hookRunner() hookRunner {
+	return hookRunner{
+		scripts: hookScripts(),
+		file:    m.ConfigFilePath,
+		timeout: time.Duration(max(1, m.Settings.HookTimeout)) * time.Second,
+	}
+}
+
+// runHook runs a hook for a change from old to new, either of which may be
+// nil, and returns the task to save in place of new.
+func (h hookRunner) runHook(script, event string, old, new *Task) (*Task, error) {
+	var stdin, stdout, stderr bytes.Buffer
+	enc := json.NewEncoder(&stdin)
+	enc.Encode(old)
+	enc.Encode(new)
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = &stdin, &stdout, &stderr
+	cmd.Env = append(os.Environ(), "TODO_EVENT="+event, "TODO_FILE="+h.file)
+	cmd.WaitDelay = 200 * time.Millisecond // for children still holding the output open
+
+	name := filepath.Base(script)
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s timed out after %s", name, h.timeout)
+	}
+	if err != nil {
+		reason := firstLine(stderr.Bytes())
+		if reason == "" {
+			reason = firstLine(stdout.Bytes())
+		}
+		if reason == "" {
+			reason = err.Error()
+		}
+		return nil, fmt.Errorf("%s: %s", name, reason)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if new == nil || len(out) == 0 {
+		return new, nil
+	}
+	var task Task
+	if err := json.Unmarshal(out, &task); err != nil {
+		return nil, fmt.Errorf("%s printed an invalid task: %v", name, err)
+	}
+	if strings.TrimSpace(task.Task) == "" {
+		return nil, fmt.Errorf("%s printed a task without text", name)
+	}
+	task.ID = new.ID
+	if task.UID == "" {
+		task.UID = new.UID
+	}
+	return &task, nil
+}
+
+// runHooks runs the hooks for event in turn.
+func (h hookRunner) runHooks(event string, old, new *Task) (*Task, error) {
+	for _, script := range h.scripts[event] {
+		var err error
+		if new, err = h.runHook(script, event, old, new); err != nil {
+			return nil, err
+		}
+	}
+	return new, nil
+}
+
+// tasksEqual reports whether two tasks have the same fields.
+func tasksEqual(a, b Task) bool {
+	tagsEqual := slices.Equal(a.Tags, b.Tags)
+	a.Tags, b.Tags = nil, nil
+	return tagsEqual && reflect.DeepEqual(a, b)
+}
+
+// taskEvent classifies the change from before, if the task existed, to task.
+func taskEvent(before Task, existed bool, task Task) (event string, changed bool) {
+	switch {
+	case !existed:
+		return HookAdd, true
+	case tasksEqual(before, task):
+		return "", false
+	case task.Checked && !before.Checked:
+		return HookComplete, true
+	}
+	return HookModify, true
+}
+
+// taskChange is a change to a task between two saves; Old is nil for an
+// added task and New for a deleted one.
+type taskChange struct {
+	Event    string
+	Old, New *Task
+}
+
+// diffTasks lists the changes from saved to current.
+func diffTasks(saved, current []Task) []taskChange {
+	before := make(map[int]Task, len(saved))
+	for _, task := range saved {
+		before[task.ID] = task
+	}
+	var changes []taskChange
+	for _, task := range current {
+		old, existed := before[task.ID]
+		if event, changed := taskEvent(old, existed, task); changed {
+			change := taskChange{Event: event, New: &task}
+			if existed {
+				change.Old = &old
+			}
+			changes = append(changes, change)
+		}
+		delete(before, task.ID)
+	}
+	for _, task := range saved {
+		if _, deleted := before[task.ID]; deleted {
+			changes = append(changes, taskChange{Event: HookDelete, Old: &task})
+		}
+	}
+	return changes
+}
+
+// hookOutcome is what the hooks made of a save.
+type hookOutcome struct {
+	Tasks   []Task   // the tasks to save
+	Refused []string // why changes were undone
+}
+
+// run runs the hooks for the changes from saved to tasks, applying the tasks
+// hooks print and undoing the changes they refuse.
+func (h hookRunner) run(saved, tasks []Task) hookOutcome {
+	outcome := hookOutcome{Tasks: slices.Clone(tasks)}
+	if len(h.scripts) == 0 {
+		return outcome
+	}
+
+	before := make(map[int]Task, len(saved))
+	for _, task := range saved {
+		before[task.ID] = task
+	}
+	for i := 0; i < len(outcome.Tasks); i++ {
+		task := outcome.Tasks[i]
+		old, existed := before[task.ID]
+		event, changed := taskEvent(old, existed, task)
+		if !changed {
+			continue
+		}
+		var oldTask *Task
+		if existed {
+			oldTask = &old
+		}
+
+		result, err := h.runHooks(event, oldTask, &task)
+		switch {
+		case err == nil:
+			outcome.Tasks[i] = *result
+		case existed:
+			outcome.Refused = append(outcome.Refused, err.Error())
+			outcome.Tasks[i] = old
+		default:
+			outcome.Refused = append(outcome.Refused, err.Error())
+			outcome.Tasks = slices.Delete(outcome.Tasks, i, i+1)
+			i--
+		}
+	}
+
+	for i, old := range saved {
+		if slices.ContainsFunc(outcome.Tasks, func(t Task) bool { return t.ID == old.ID }) {
+			continue
+		}
+		if _, err := h.runHooks(HookDelete, &old, nil); err != nil {
+			outcome.Refused = append(outcome.Refused, err.Error())
+			outcome.Tasks = slices.Insert(outcome.Tasks, min(i, len(outcome.Tasks)), old)
+		}
+	}
+	return outcome
+}
+
+// sameTasks reports whether a and b hold the same tasks, in any order.
+func sameTasks(a, b []Task) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byID := make(map[int]Task, len(a))
+	for _, task := range a {
+		byID[task.ID] = task
+	}
+	for _, task := range b {
+		if other, ok := byID[task.ID]; !ok || !tasksEqual(task, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyHookOutcome takes what the hooks made of before, the tasks when they
+// started, into the model. Tasks changed since are left for the next save.
+func (m *Model) applyHookOutcome(saved, before []Task, outcome hookOutcome) {
+	if len(outcome.Refused) > 0 {
+		m.ErrorMessage = strings.Join(outcome.Refused, "; ")
+	}
+	if slices.EqualFunc(before, outcome.Tasks, tasksEqual) {
+		return
+	}
+
+	if slices.EqualFunc(m.Tasks, before, tasksEqual) {
+		m.Tasks = slices.Clone(outcome.Tasks)
+	} else {
+		started := make(map[int]Task, len(before))
+		for _, task := range before {
+			started[task.ID] = task
+		}
+		hooked := make(map[int]Task, len(outcome.Tasks))
+		for _, task := range outcome.Tasks {
+			hooked[task.ID] = task
+		}
+		var tasks []Task
+		for _, task := range m.Tasks {
+			if old, ok := started[task.ID]; ok && tasksEqual(old, task) {
+				if result, ok := hooked[task.ID]; ok {
+					tasks = append(tasks, result)
+				}
+				continue
+			}
+			tasks = append(tasks, task)
+		}
+		for _, task := range outcome.Tasks {
+			if _, ok := started[task.ID]; !ok && !slices.ContainsFunc(tasks, func(t Task) bool { return t.ID == task.ID }) {
+				tasks = append(tasks, task) // a deletion the hooks refused
+			}
+		}
+		m.Tasks = tasks
+	}
+
+	// A change the hooks refused outright leaves nothing to undo.
+	if len(outcome.Refused) > 0 && sameTasks(outcome.Tasks, saved) {
+		for i := len(m.History) - 1; i >= 0; i-- {
+			if sameTasks(m.History[i].Tasks, saved) {
+				m.History = slices.Delete(m.History, i, i+1)
+				break
+			}
+		}
+	}
+	m.UpdateContexts()
+	m.SelectedIndex = max(0, min(m.SelectedIndex, len(m.GetFilteredTasks())-1))
+}
+
+// hooksResultMsg carries the outcome of a background hook round.
+type hooksResultMsg struct {
+	round   int
+	saved   []Task
+	before  []Task
+	outcome hookOutcome
+}
+
+// startHooks runs the hooks for the unsaved changes in the background.
+func (m *Model) startHooks() tea.Cmd {
+	m.HookRound++
+	m.HooksRunning = true
+	round, runner := m.HookRound, m.hookRunner()
+	saved, before := m.SavedTasks, slices.Clone(m.Tasks)
+	return func() tea.Msg {
+		return hooksResultMsg{round: round, saved: saved, before: before, outcome: runner.run(saved, before)}
+	}
+}
+
+// updateHooks saves what the hooks made of a change when they finish, and
+// starts them when a save is waiting and none are running. Saves without
+// changes for hooks to see are written straight away.
+func (m Model) updateHooks(msg tea.Msg, cmd tea.Cmd) (Model, tea.Cmd) {
+	if msg, ok := msg.(hooksResultMsg); ok && msg.round == m.HookRound && m.HooksRunning {
+		m.HooksRunning = false
+		m.applyHookOutcome(msg.saved, msg.before, msg.outcome)
+		m.writeNoteFile(msg.outcome.Tasks)
+		if !slices.EqualFunc(m.Tasks, msg.outcome.Tasks, tasksEqual) {
+			m.HooksQueued = true
+		}
+	}
+	if m.HooksQueued && !m.HooksRunning {
+		m.HooksQueued = false
+		if len(hookScripts()) == 0 || len(diffTasks(m.SavedTasks, m.Tasks)) == 0 {
+			m.SaveConfigNow()
+		} else {
+			cmd = tea.Batch(cmd, m.startHooks())
+		}
+	}
+	return m, cmd
+}
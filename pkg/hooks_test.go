@@ -0,0 +1,233 @@
+package todo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// writeHook installs an executable shell script as the hook name.
+func writeHook(t *testing.T, name, script string) {
+	t.Helper()
+	if err := os.MkdirAll(HooksDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(HooksDir(), name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hookModel returns testModel saved to a note file in an isolated home.
+func hookModel(t *testing.T) *Model {
+	t.Helper()
+	home := isolateHome(t)
+	m := testModel()
+	m.MaxHistory = 10
+	m.ConfigFilePath = filepath.Join(home, "note.json")
+	m.SaveConfig()
+	return m
+}
+
+// savedTasks reads the tasks from m's note file.
+func savedTasks(t *testing.T, m *Model) []Task {
+	t.Helper()
+	data, err := os.ReadFile(m.ConfigFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file noteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	return file.Tasks
+}
+
+func TestHookStdin(t *testing.T) {
+	m := hookModel(t)
+	out := filepath.Join(t.TempDir(), "stdin")
+	for _, event := range []string{HookAdd, HookModify, HookComplete, HookDelete} {
+		writeHook(t, event, `{ echo "$TODO_EVENT $TODO_FILE"; cat; } >> `+out)
+	}
+
+	m.AddTask("New task")
+	added := m.Tasks[len(m.Tasks)-1]
+	m.SaveConfig()
+	old := m.Tasks[2]
+	m.Tasks[2].Priority = "high"
+	m.SaveConfig()
+	m.Tasks = m.Tasks[:len(m.Tasks)-1]
+	m.SaveConfig()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("hooks got %d lines:\n%s", len(lines), data)
+	}
+	encode := func(task *Task) string {
+		b, _ := json.Marshal(task)
+		return string(b)
+	}
+	modified := m.Tasks[2]
+	want := []string{
+		HookAdd + " " + m.ConfigFilePath, "null", encode(&added),
+		HookModify + " " + m.ConfigFilePath, encode(&old), encode(&modified),
+		HookDelete + " " + m.ConfigFilePath, encode(&added), "null",
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %s, want %s", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestHookRefuses(t *testing.T) {
+	m := hookModel(t)
+	writeHook(t, HookModify, `echo "not on a Sunday" >&2; exit 1`)
+	writeHook(t, HookDelete, `echo "keep it"; exit 3`)
+
+	m.SaveStateForUndo()
+	m.Tasks[0].Task = "Changed"
+	m.SaveConfig()
+	if m.Tasks[0].Task != "Ship the release, v2; then rest" {
+		t.Errorf("refused edit kept: %+v", m.Tasks[0])
+	}
+	if m.ErrorMessage != "on-modify: not on a Sunday" {
+		t.Errorf("ErrorMessage = %q", m.ErrorMessage)
+	}
+	if len(m.History) != 0 {
+		t.Errorf("refused edit left %d undo entries", len(m.History))
+	}
+	if got := savedTasks(t, m); got[0].Task != "Ship the release, v2; then rest" {
+		t.Errorf("refused edit saved: %+v", got[0])
+	}
+
+	m.ErrorMessage = ""
+	m.Tasks = m.Tasks[1:]
+	m.SaveConfig()
+	if len(m.Tasks) != 3 || len(savedTasks(t, m)) != 3 || m.ErrorMessage != "on-delete: keep it" {
+		t.Errorf("refused delete: %d tasks, ErrorMessage %q", len(m.Tasks), m.ErrorMessage)
+	}
+}
+
+func TestHookRewrites(t *testing.T) {
+	m := hookModel(t)
+	writeHook(t, HookAdd+".first", `echo '{"task": "Rewritten", "context": "Work", "tags": ["hooked"]}'`)
+	writeHook(t, HookAdd+".second", `read old; read new; case "$new" in *hooked*) ;; *) exit 1 ;; esac`)
+
+	m.AddTask("Original")
+	want := m.Tasks[len(m.Tasks)-1]
+	m.SaveConfig()
+	got := m.Tasks[len(m.Tasks)-1]
+	if got.Task != "Rewritten" || len(got.Tags) != 1 || got.ID != want.ID || got.UID != want.UID {
+		t.Errorf("rewritten task %+v, added %+v", got, want)
+	}
+	if m.ErrorMessage != "" {
+		t.Errorf("ErrorMessage = %q", m.ErrorMessage)
+	}
+	if saved := savedTasks(t, m); !tasksEqual(saved[len(saved)-1], got) {
+		t.Errorf("saved %+v", saved[len(saved)-1])
+	}
+}
+
+func TestHookTimeout(t *testing.T) {
+	m := hookModel(t)
+	m.Settings.HookTimeout = 1
+	writeHook(t, HookAdd, `exec sleep 10`)
+
+	start := time.Now()
+	m.AddTask("Slow")
+	m.SaveConfig()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook ran for %s", elapsed)
+	}
+	if len(m.Tasks) != 3 || m.ErrorMessage != "on-add timed out after 1s" {
+		t.Errorf("%d tasks, ErrorMessage %q", len(m.Tasks), m.ErrorMessage)
+	}
+}
+
+func TestHooksRunInBackground(t *testing.T) {
+	m := hookModel(t)
+	m.AsyncHooks = true
+	writeHook(t, HookModify, `echo "refused" >&2; exit 1`)
+
+	m.SaveStateForUndo()
+	m.Tasks[0].Priority = "low"
+	m.SaveConfig()
+	if !m.HooksQueued {
+		t.Fatal("save did not wait for hooks")
+	}
+	model, cmd := m.updateHooks(nil, nil)
+	if !model.HooksRunning || cmd == nil {
+		t.Fatal("hooks not started")
+	}
+
+	// Edits made while the hooks run are kept for the next save.
+	model.AddTask("Added meanwhile")
+	msg := cmd()
+	model, cmd = model.updateHooks(msg, nil)
+	if model.Tasks[0].Priority != "high" || model.ErrorMessage != "on-modify: refused" {
+		t.Errorf("priority %q, ErrorMessage %q", model.Tasks[0].Priority, model.ErrorMessage)
+	}
+	if len(model.History) != 0 {
+		t.Errorf("refused edit left %d undo entries", len(model.History))
+	}
+	if len(model.Tasks) != 4 || !model.HooksRunning || cmd == nil {
+		t.Fatalf("tasks %+v, next round started %v", model.Tasks, model.HooksRunning)
+	}
+
+	// A stale round's result is ignored.
+	stale := msg.(hooksResultMsg)
+	model, _ = model.updateHooks(stale, nil)
+	if !model.HooksRunning {
+		t.Error("stale result ended the running round")
+	}
+
+	model, _ = model.updateHooks(cmd(), nil)
+	if model.HooksRunning || model.HooksQueued {
+		t.Error("hooks still pending")
+	}
+	if got := savedTasks(t, &model); len(got) != 4 || got[0].Priority != "high" || got[3].Task != "Added meanwhile" {
+		t.Errorf("saved %+v", got)
+	}
+}
+
+func TestHookRefusalShownOnBoard(t *testing.T) {
+	m := kanbanTestModel(t)
+	m.AsyncHooks = true
+	writeHook(t, HookModify, `echo "priorities are frozen" >&2; exit 1`)
+	before, _ := m.KanbanFocusedTask()
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = model.(Model)
+	if !m.HooksRunning || cmd == nil {
+		t.Fatal("hooks not started")
+	}
+	for {
+		msg := cmd()
+		if _, ok := msg.(hooksResultMsg); ok {
+			model, _ = m.Update(msg)
+			m = model.(Model)
+			break
+		}
+		batch, ok := msg.(tea.BatchMsg)
+		if !ok || len(batch) == 0 {
+			t.Fatalf("unexpected message %T", msg)
+		}
+		cmd = batch[len(batch)-1]
+	}
+
+	if task, _ := m.KanbanFocusedTask(); task.Priority != before.Priority {
+		t.Errorf("refused priority kept: %q", task.Priority)
+	}
+	if view := m.View(); !strings.Contains(view, "on-modify: priorities are frozen") {
+		t.Errorf("board does not show the refusal:\n%s", view)
+	}
+}
@@ -0,0 +1,294 @@
+package todo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar (RFC 5545). Each task is a VTODO with the task's UID, so calendar
+// clients keep track of it across exports and importing an edited calendar
+// updates the tasks it came from. CATEGORIES holds the context followed by
+// the tags; on import the first category is taken as the context. Statuses
+// between the first and last of the workflow are written as IN-PROCESS, with
+// the exact name in X-SRN-TODO-STATUS.
+
+const (
+	icsLineLimit  = 75 // octets per line, excluding the CRLF
+	icsDateLayout = "20060102"
+	icsStatusProp = "X-SRN-TODO-STATUS"
+)
+
+// icsPriorities maps priorities to their RFC 5545 value; on import 1-4 count
+// as high, 5 as medium and 6-9 as low.
+var icsPriorities = map[string]int{"high": 1, "medium": 5, "low": 9}
+
+// icsEscape escapes a TEXT value.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icsUnescape decodes a TEXT value.
+func icsUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// icsSplitList splits a list value on unescaped commas and unescapes each
+// item.
+func icsSplitList(s string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, icsUnescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, icsUnescape(s[start:]))
+}
+
+// icsFold splits a content line into lines of at most 75 octets, without
+// breaking UTF-8 sequences. Continuation lines start with a space.
+func icsFold(line string) string {
+	var b strings.Builder
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
+
+// WriteICS writes every task as a VTODO of one calendar.
+func (m *Model) WriteICS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(format string, args ...any) {
+		bw.WriteString(icsFold(fmt.Sprintf(format, args...)))
+	}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//srn-todo//srn-todo//EN")
+	for _, task := range m.Tasks {
+		line("BEGIN:VTODO")
+		line("UID:%s", icsEscape(task.UID))
+		line("DTSTAMP:%s", stamp)
+		line("SUMMARY:%s", icsEscape(task.Task))
+		if task.DueDate != "" {
+			if due, err := time.Parse(time.DateOnly, task.DueDate); err == nil {
+				line("DUE;VALUE=DATE:%s", due.Format(icsDateLayout))
+			}
+		}
+		if p, ok := icsPriorities[task.Priority]; ok {
+			line("PRIORITY:%d", p)
+		}
+		categories := []string{icsEscape(task.Context)}
+		for _, tag := range task.Tags {
+			categories = append(categories, icsEscape(tag))
+		}
+		line("CATEGORIES:%s", strings.Join(categories, ","))
+		switch i := m.StatusIndex(task); {
+		case task.Checked:
+			line("STATUS:COMPLETED")
+		case i > 0 && i < len(m.Statuses)-1:
+			line("STATUS:IN-PROCESS")
+			line("%s:%s", icsStatusProp, icsEscape(task.Status))
+		default:
+			line("STATUS:NEEDS-ACTION")
+		}
+		if task.Notes != "" {
+			line("DESCRIPTION:%s", icsEscape(task.Notes))
+		}
+		line("END:VTODO")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsLine is an unfolded content line.
+type icsLine struct {
+	n     int // line number where it starts
+	name  string
+	value string
+}
+
+// icsUnfold joins folded lines and splits each into its name and value.
+// Parameters are dropped; none of the properties read here need them.
+func icsUnfold(r io.Reader) ([]icsLine, error) {
+	var raw []icsLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(raw) > 0 {
+			raw[len(raw)-1].value += text[1:]
+			continue
+		}
+		if text != "" {
+			raw = append(raw, icsLine{n: n, value: text})
+		}
+	}
+
+	lines := raw[:0]
+	for _, l := range raw {
+		// The value starts after the first colon outside a quoted parameter.
+		quoted, colon := false, -1
+		for i := 0; i < len(l.value) && colon == -1; i++ {
+			switch l.value[i] {
+			case '"':
+				quoted = !quoted
+			case ':':
+				if !quoted {
+					colon = i
+				}
+			}
+		}
+		if colon == -1 {
+			continue
+		}
+		name, _, _ := strings.Cut(l.value[:colon], ";")
+		l.name, l.value = strings.ToUpper(name), l.value[colon+1:]
+		lines = append(lines, l)
+	}
+	return lines, scanner.Err()
+}
+
+// setICSProperty applies a VTODO property to task.
+func setICSProperty(task *Task, categories *[]string, name, value string) error {
+	switch name {
+	case "UID":
+		task.UID = icsUnescape(value)
+	case "SUMMARY":
+		task.Task = strings.TrimSpace(icsUnescape(value))
+	case "DESCRIPTION":
+		task.Notes = icsUnescape(value)
+	case "DUE":
+		// A date, or a date-time of which only the date is kept.
+		due, err := time.Parse(icsDateLayout, value[:min(len(value), len(icsDateLayout))])
+		if err != nil {
+			return fmt.Errorf("invalid DUE %q", value)
+		}
+		task.DueDate = due.Format(time.DateOnly)
+	case "PRIORITY":
+		p, err := strconv.Atoi(value)
+		switch {
+		case err != nil || p < 0 || p > 9:
+			return fmt.Errorf("invalid PRIORITY %q", value)
+		case p == 0:
+			task.Priority = ""
+		case p < 5:
+			task.Priority = "high"
+		case p == 5:
+			task.Priority = "medium"
+		default:
+			task.Priority = "low"
+		}
+	case "STATUS":
+		task.Checked = strings.EqualFold(value, "COMPLETED")
+	case "COMPLETED":
+		task.Checked = true
+	case icsStatusProp:
+		task.Status = icsUnescape(value)
+	case "CATEGORIES":
+		for _, category := range icsSplitList(value) {
+			if category = strings.TrimSpace(category); category != "" {
+				*categories = append(*categories, category)
+			}
+		}
+	}
+	return nil
+}
+
+// ImportICS reads the VTODOs of a calendar. Other components, such as
+// events, are ignored.
+func ImportICS(r io.Reader) (ImportResult, error) {
+	var result ImportResult
+	lines, err := icsUnfold(r)
+	if err != nil {
+		return result, err
+	}
+
+	var (
+		task       *Task
+		categories []string
+		errs       []error
+		start      int
+		nested     int // depth of components inside the VTODO, such as VALARM
+	)
+	for _, l := range lines {
+		switch {
+		case task == nil:
+			if l.name == "BEGIN" && strings.EqualFold(l.value, "VTODO") {
+				task, categories, errs, start, nested = &Task{}, nil, nil, l.n, 0
+			}
+		case l.name == "BEGIN":
+			nested++
+		case l.name == "END" && nested > 0:
+			nested--
+		case l.name == "END":
+			if len(categories) > 0 {
+				task.Context = cleanContextName(categories[0])
+				task.Tags = categories[1:]
+			}
+			if task.Task == "" {
+				errs = append(errs, errors.New("no SUMMARY"))
+			}
+			if err := errors.Join(errs...); err != nil {
+				problem := strings.ReplaceAll(err.Error(), "\n", "; ")
+				result.Skipped = append(result.Skipped, fmt.Sprintf("VTODO at line %d: %s", start, problem))
+			} else {
+				result.Tasks = append(result.Tasks, *task)
+			}
+			task = nil
+		case nested == 0:
+			errs = append(errs, setICSProperty(task, &categories, l.name, l.value))
+		}
+	}
+	if task != nil {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("VTODO at line %d: missing END:VTODO", start))
+	}
+	return result, nil
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "ics",
+		Extensions:  []string{".ics", ".ical"},
+		Description: "iCalendar to-dos",
+		Export: func(w io.Writer, m *Model) error {
+			return m.WriteICS(w)
+		},
+		Import: func(r io.Reader, _ ImportOptions) (ImportResult, error) {
+			return ImportICS(r)
+		},
+	})
+}
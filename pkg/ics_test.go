@@ -0,0 +1,163 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestICSFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Buy milk"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68)},
+		{"long ASCII", "DESCRIPTION:" + strings.Repeat("0123456789", 30)},
+		{"two-byte runes", "SUMMARY:" + strings.Repeat("é", 100)},
+		{"three-byte runes", "SUMMARY:" + strings.Repeat("日本語", 40)},
+		{"four-byte runes", "SUMMARY:x" + strings.Repeat("😀", 50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := icsFold(tt.line)
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("folded line does not end with CRLF: %q", folded)
+			}
+			lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > icsLineLimit {
+					t.Errorf("line %d has %d octets: %q", i, len(line), line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+			}
+			if len(tt.line) <= icsLineLimit && len(lines) != 1 {
+				t.Errorf("short line folded into %d lines", len(lines))
+			}
+
+			unfolded, err := icsUnfold(strings.NewReader(folded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			name, value, _ := strings.Cut(tt.line, ":")
+			if len(unfolded) != 1 || unfolded[0].name != name || unfolded[0].value != value {
+				t.Errorf("unfolded to %+v, want %s:%s", unfolded, name, value)
+			}
+		})
+	}
+}
+
+func TestICSEscape(t *testing.T) {
+	tests := []struct {
+		text, escaped string
+	}{
+		{"plain", "plain"},
+		{`back\slash`, `back\\slash`},
+		{"semi;colon", `semi\;colon`},
+		{"com,ma", `com\,ma`},
+		{"two\nlines", `two\nlines`},
+		{"crlf\r\nline", `crlf\nline`},
+		{`all\;,` + "\n", `all\\\;\,\n`},
+		{`\n`, `\\n`},
+	}
+	for _, tt := range tests {
+		if got := icsEscape(tt.text); got != tt.escaped {
+			t.Errorf("icsEscape(%q) = %q, want %q", tt.text, got, tt.escaped)
+		}
+		want := strings.ReplaceAll(tt.text, "\r\n", "\n")
+		if got := icsUnescape(tt.escaped); got != want {
+			t.Errorf("icsUnescape(%q) = %q, want %q", tt.escaped, got, want)
+		}
+	}
+	if got := icsUnescape(`upper\Ncase`); got != "upper\ncase" {
+		t.Errorf(`icsUnescape(\N) = %q`, got)
+	}
+}
+
+func TestICSSplitList(t *testing.T) {
+	got := icsSplitList(`Work,a\,b,c\;d,`)
+	want := []string{"Work", "a,b", "c;d", ""}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("icsSplitList() = %q, want %q", got, want)
+	}
+}
+
+func TestICSRoundTripKeepsUIDAndStatus(t *testing.T) {
+	m := testModel()
+	var buf bytes.Buffer
+	if err := m.WriteICS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"UID:" + m.Tasks[0].UID + "\r\n",
+		"STATUS:IN-PROCESS\r\n" + icsStatusProp + ":In Progress\r\n",
+		"STATUS:COMPLETED\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		`SUMMARY:Ship the release\, v2\; then rest`,
+		`DESCRIPTION:First line\nsecond line`,
+		"CATEGORIES:Work,deploy,q3\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("export lacks %q:\n%s", want, out)
+		}
+	}
+
+	result, err := ImportICS(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != len(m.Tasks) {
+		t.Fatalf("imported %d tasks, want %d", len(result.Tasks), len(m.Tasks))
+	}
+	for i, task := range result.Tasks {
+		if task.UID != m.Tasks[i].UID {
+			t.Errorf("task %d UID = %q, want %q", i, task.UID, m.Tasks[i].UID)
+		}
+	}
+	if result.Tasks[0].Status != "In Progress" {
+		t.Errorf("status = %q, want In Progress", result.Tasks[0].Status)
+	}
+}
+
+func TestImportICSSkipsInvalidTodos(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:Not a task",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"SUMMARY:Has an alarm",
+		"BEGIN:VALARM",
+		"SUMMARY:Alarm text",
+		"END:VALARM",
+		"DUE;TZID=Europe/Berlin:20261020T090000",
+		"PRIORITY:3",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"DUE:soon",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	result, err := ImportICS(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != 1 {
+		t.Fatalf("imported %+v", result.Tasks)
+	}
+	task := result.Tasks[0]
+	if task.Task != "Has an alarm" || task.DueDate != "2026-10-20" || task.Priority != "high" {
+		t.Errorf("imported %+v", task)
+	}
+	if len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0], "line 13") || !strings.Contains(result.Skipped[0], "no SUMMARY") {
+		t.Errorf("skipped %q", result.Skipped)
+	}
+}
@@ -0,0 +1,252 @@
+package todo
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const kanbanHeaderLines = 2 // column title and rule
+
+// kanbanColumn is a single column of the kanban board. Exactly one of Context
+// and Status is set, depending on the layout.
+type kanbanColumn struct {
+	Title   string
+	Context string
+	Status  string
+	Tasks   []Task
+}
+
+func (m *Model) kanbanColumns() []kanbanColumn {
+	if m.KanbanLayout == KanbanByStatus {
+		columns := make([]kanbanColumn, 0, len(m.Statuses))
+		for _, status := range m.Statuses {
+			column := kanbanColumn{Title: status, Status: status}
+			for _, task := range m.GetFilteredTasks() {
+				if task.Status == status {
+					column.Tasks = append(column.Tasks, task)
+				}
+			}
+			columns = append(columns, column)
+		}
+		return columns
+	}
+
+	contexts := m.VisibleContexts()
+	columns := make([]kanbanColumn, 0, len(contexts))
+	for _, context := range contexts {
+		if m.aggregatedIntoParent(context) {
+			continue
+		}
+		columns = append(columns, kanbanColumn{
+			Title:   context,
+			Context: context,
+			Tasks:   m.GetTasksForContext(context),
+		})
+	}
+	return columns
+}
+
+// kanbanColumnStride is the width of a column on the board, used alike for
+// rendering, scrolling and hit testing.
+func (m *Model) kanbanColumnStride() int {
+	return max(1, m.Settings.KanbanColumnWidth)
+}
+
+func (m *Model) kanbanVisibleCols() int {
+	return max(1, (m.WindowWidth-m.Theme.Base.GetHorizontalFrameSize())/m.kanbanColumnStride())
+}
+
+func (m *Model) kanbanBoardHeight() int {
+	return m.WindowHeight - lipgloss.Height(m.kanbanTitle()) - 1 - len(m.kanbanFooterLines())
+}
+
+// kanbanFooterLines returns the lines below the board: the error message,
+// if any.
+func (m *Model) kanbanFooterLines() []string {
+	if m.ErrorMessage == "" {
+		return nil
+	}
+	return []string{"", m.Theme.Error.Render(m.ErrorMessage)}
+}
+
+// KanbanFocusedTask returns the card under the kanban cursor, if any.
+func (m *Model) KanbanFocusedTask() (Task, bool) {
+	columns := m.kanbanColumns()
+	if m.KanbanCol < 0 || m.KanbanCol >= len(columns) {
+		return Task{}, false
+	}
+	tasks := columns[m.KanbanCol].Tasks
+	if m.KanbanRow < 0 || m.KanbanRow >= len(tasks) {
+		return Task{}, false
+	}
+	return tasks[m.KanbanRow], true
+}
+
+// ShowKanbanView switches to the board with the cursor on the current task.
+func (m *Model) ShowKanbanView() {
+	m.ViewMode = KanbanView
+	m.MovingMode = false
+	m.KanbanScrollX = 0
+	m.KanbanScrollY = 0
+	m.KanbanCol = 0
+	if m.KanbanLayout == KanbanByContext {
+		m.KanbanCol = max(0, slices.Index(m.VisibleContexts(), m.CurrentContext))
+	}
+	m.KanbanRow = 0
+	m.refocusKanban()
+}
+
+// ToggleKanbanLayout switches the board between grouping by context and by
+// workflow status within the current context.
+func (m *Model) ToggleKanbanLayout() {
+	if m.KanbanLayout == KanbanByContext {
+		m.KanbanLayout = KanbanByStatus
+	} else {
+		m.KanbanLayout = KanbanByContext
+	}
+	m.ShowKanbanView()
+}
+
+// syncKanbanSelection clamps the kanban cursor and points CurrentContext and
+// SelectedIndex at the focused card, so the *CurrentTask methods act on it.
+func (m *Model) syncKanbanSelection() {
+	columns := m.kanbanColumns()
+	if len(columns) == 0 {
+		m.KanbanCol, m.KanbanRow = 0, 0
+		return
+	}
+	m.KanbanCol = max(0, min(m.KanbanCol, len(columns)-1))
+	column := columns[m.KanbanCol]
+	m.KanbanRow = max(0, min(m.KanbanRow, len(column.Tasks)-1))
+
+	if column.Context != "" && column.Context != m.CurrentContext {
+		m.CurrentContext = column.Context
+		m.SelectedIndex = 0
+	}
+	if task, ok := m.KanbanFocusedTask(); ok {
+		idx := slices.IndexFunc(m.GetFilteredTasks(), func(t Task) bool {
+			return t.ID == task.ID
+		})
+		if idx != -1 {
+			m.SelectedIndex = idx
+		}
+	}
+	m.scrollKanbanToCursor()
+}
+
+// focusKanbanTask moves the kanban cursor onto the card with the given ID.
+func (m *Model) focusKanbanTask(id int) bool {
+	for c, column := range m.kanbanColumns() {
+		r := slices.IndexFunc(column.Tasks, func(t Task) bool {
+			return t.ID == id
+		})
+		if r != -1 {
+			m.KanbanCol, m.KanbanRow = c, r
+			m.syncKanbanSelection()
+			return true
+		}
+	}
+	return false
+}
+
+// refocusKanban puts the kanban cursor back on the current task after it was
+// changed outside the board, e.g. by an input dialog.
+func (m *Model) refocusKanban() {
+	if task := m.GetCurrentTask(); task.ID == 0 || !m.focusKanbanTask(task.ID) {
+		m.syncKanbanSelection()
+	}
+}
+
+func (m *Model) MoveKanbanCursor(dCol, dRow int) {
+	m.KanbanCol += dCol
+	m.KanbanRow += dRow
+	m.syncKanbanSelection()
+}
+
+// ShiftKanbanCard moves the focused card into a neighbouring column, keeping
+// its row where possible.
+func (m *Model) ShiftKanbanCard(dCol int) {
+	task, ok := m.KanbanFocusedTask()
+	if !ok {
+		return
+	}
+	columns := m.kanbanColumns()
+	target := m.KanbanCol + dCol
+	if target < 0 || target >= len(columns) {
+		return
+	}
+	idx := m.findTaskIndexByID(task.ID)
+	if idx == -1 {
+		return
+	}
+	if status := columns[target].Status; status != "" {
+		m.setTaskStatus(idx, status)
+		m.focusKanbanTask(task.ID)
+		return
+	}
+	moved := m.Tasks[idx]
+	m.Tasks = slices.Delete(m.Tasks, idx, idx+1)
+	moved.Context = columns[target].Context
+
+	insertAt := len(m.Tasks)
+	if targetTasks := columns[target].Tasks; m.KanbanRow < len(targetTasks) {
+		insertAt = m.findTaskIndexByID(targetTasks[m.KanbanRow].ID)
+	}
+	m.Tasks = slices.Insert(m.Tasks, insertAt, moved)
+	m.focusKanbanTask(moved.ID)
+}
+
+// ReorderKanbanCard swaps the focused card with its neighbour in the column.
+func (m *Model) ReorderKanbanCard(dRow int) {
+	task, ok := m.KanbanFocusedTask()
+	if !ok {
+		return
+	}
+	tasks := m.kanbanColumns()[m.KanbanCol].Tasks
+	target := m.KanbanRow + dRow
+	if target < 0 || target >= len(tasks) {
+		return
+	}
+	idxMove := m.findTaskIndexByID(task.ID)
+	idxSwap := m.findTaskIndexByID(tasks[target].ID)
+	if idxMove != -1 && idxSwap != -1 {
+		m.Tasks[idxMove], m.Tasks[idxSwap] = m.Tasks[idxSwap], m.Tasks[idxMove]
+		m.KanbanRow = target
+		m.syncKanbanSelection()
+	}
+}
+
+// scrollKanbanToCursor adjusts the board viewport so the focused card is
+// fully visible.
+func (m *Model) scrollKanbanToCursor() {
+	visibleCols := m.kanbanVisibleCols()
+	if m.KanbanCol < m.KanbanScrollX {
+		m.KanbanScrollX = m.KanbanCol
+	} else if m.KanbanCol >= m.KanbanScrollX+visibleCols {
+		m.KanbanScrollX = m.KanbanCol - visibleCols + 1
+	}
+
+	height := m.kanbanBoardHeight()
+	columns := m.kanbanColumns()
+	if height <= 0 || m.KanbanCol >= len(columns) {
+		return
+	}
+	if m.KanbanRow == 0 {
+		m.KanbanScrollY = 0
+		return
+	}
+	top := kanbanHeaderLines
+	for i, task := range columns[m.KanbanCol].Tasks {
+		cardHeight := lipgloss.Height(m.renderKanbanCard(task, false, false))
+		if i == m.KanbanRow {
+			if top < m.KanbanScrollY {
+				m.KanbanScrollY = top
+			} else if top+cardHeight > m.KanbanScrollY+height {
+				m.KanbanScrollY = top + cardHeight - height
+			}
+			return
+		}
+		top += cardHeight
+	}
+}
@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// kanbanTestModel opens a fresh note file in an isolated home and shows it
+// on the board.
+func kanbanTestModel(t *testing.T) Model {
+	t.Helper()
+	home := isolateHome(t)
+	m := Initialize(filepath.Join(home, "note.json"), DefaultSettings())
+	m.WindowWidth, m.WindowHeight = 120, 30
+	m.ShowKanbanView()
+	return m
+}
+
+var keyTypes = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+}
+
+// press sends the keys to m in turn, named as in keyTypes or typed as text.
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if t, ok := keyTypes[k]; ok {
+			msg = tea.KeyMsg{Type: t}
+		}
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	return m
+}
+
+func TestKanbanViewShowsErrors(t *testing.T) {
+	m := press(kanbanTestModel(t), "z")
+	if m.ErrorMessage != "Nothing to undo" {
+		t.Fatalf("ErrorMessage = %q", m.ErrorMessage)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Nothing to undo") {
+		t.Errorf("board does not show the error:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n") + 1; lines > m.WindowHeight {
+		t.Errorf("board is %d lines high in a %d line window", lines, m.WindowHeight)
+	}
+
+	m = press(m, "j")
+	if strings.Contains(m.View(), "Nothing to undo") {
+		t.Error("error still shown after the next key")
+	}
+}
@@ -0,0 +1,202 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Keybinding overrides live in keys.json in the config directory, keyed by
+// KeyMap field name:
+//
+//	{
+//	  "Delete": ["x"],
+//	  "Quit": ["ctrl+c"],
+//	  "Move": []
+//	}
+//
+// An empty list unbinds the action.
+
+// navAction is the help-only binding summarising the arrow keys; it can't be
+// rebound directly.
+const navAction = "Nav"
+
+// KeyAction is a named entry of a KeyMap.
+type KeyAction struct {
+	Name    string
+	Binding key.Binding
+}
+
+// ConfigDir returns the srn-todo directory under the user's config dir
+// ($XDG_CONFIG_HOME or ~/.config).
+func ConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, "srn-todo")
+}
+
+// KeysFilePath returns the path of the keybinding override file.
+func KeysFilePath() string {
+	return filepath.Join(ConfigDir(), "keys.json")
+}
+
+// Actions lists the bindable actions of the key map in declaration order.
+func (k KeyMap) Actions() []KeyAction {
+	v := reflect.ValueOf(k)
+	actions := make([]KeyAction, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		binding, ok := v.Field(i).Interface().(key.Binding)
+		if ok && v.Type().Field(i).Name != navAction {
+			actions = append(actions, KeyAction{Name: v.Type().Field(i).Name, Binding: binding})
+		}
+	}
+	return actions
+}
+
+// LoadKeyMap returns the default key map with the overrides from path
+// applied. A missing file is not an error. On error the defaults are returned
+// alongside every problem found.
+func LoadKeyMap(path string) (KeyMap, error) {
+	defaults := DefaultKeyMap()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
+	}
+
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return defaults, fmt.Errorf("%s: %w", path, err)
+	}
+
+	km := defaults
+	v := reflect.ValueOf(&km).Elem()
+	var errs []error
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		field := v.FieldByName(name)
+		if name == navAction || !field.IsValid() {
+			errs = append(errs, fmt.Errorf("unknown action %q", name))
+			continue
+		}
+		keys, err := parseKeyList(overrides[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		binding := field.Interface().(key.Binding)
+		field.Set(reflect.ValueOf(rebind(binding, keys)))
+	}
+	errs = append(errs, km.conflicts()...)
+	if len(errs) > 0 {
+		return defaults, fmt.Errorf("%s: %w", path, errors.Join(errs...))
+	}
+
+	km.Nav = key.NewBinding(
+		key.WithKeys(km.Nav.Keys()...),
+		key.WithHelp(navHelp(km), km.Nav.Help().Desc),
+	)
+	return km, nil
+}
+
+// parseKeyList accepts either a single key or a list of keys.
+func parseKeyList(raw json.RawMessage) ([]string, error) {
+	var keys []string
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &keys); err != nil {
+			return nil, err
+		}
+	} else {
+		var single string
+		if err := json.Unmarshal(raw, &single); err != nil {
+			return nil, errors.New("expected a key or a list of keys")
+		}
+		keys = []string{single}
+	}
+	for _, k := range keys {
+		if k == "" {
+			return nil, errors.New("empty key")
+		}
+	}
+	return keys, nil
+}
+
+// rebind returns a copy of binding triggered by keys, with its help text
+// updated to match.
+func rebind(binding key.Binding, keys []string) key.Binding {
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled(), key.WithHelp("", binding.Help().Desc))
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = keyDisplayName(k)
+	}
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(names, "/"), binding.Help().Desc),
+	)
+}
+
+func keyDisplayName(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+	return k
+}
+
+// navHelp summarises the movement keys for the Nav help entry.
+func navHelp(km KeyMap) string {
+	var parts []string
+	for _, b := range []key.Binding{km.Up, km.Down, km.Left, km.Right} {
+		if b.Enabled() {
+			parts = append(parts, keyDisplayName(b.Keys()[0]))
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// conflicts reports keys bound to more than one action.
+func (k KeyMap) conflicts() []error {
+	owners := make(map[string]string)
+	var errs []error
+	for _, action := range k.Actions() {
+		if !action.Binding.Enabled() {
+			continue
+		}
+		for _, bound := range action.Binding.Keys() {
+			if owner, ok := owners[bound]; ok {
+				errs = append(errs, fmt.Errorf("key %q is bound to both %s and %s", bound, owner, action.Name))
+				continue
+			}
+			owners[bound] = action.Name
+		}
+	}
+	return errs
+}
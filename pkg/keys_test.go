@@ -0,0 +1,98 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefaultKeyMapHasNoConflicts(t *testing.T) {
+	if errs := DefaultKeyMap().conflicts(); len(errs) > 0 {
+		t.Fatalf("default key map conflicts: %v", errs)
+	}
+}
+
+func TestLoadKeyMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string // substring of the error, empty for none
+		check   func(t *testing.T, km KeyMap)
+	}{
+		{
+			name: "list",
+			file: `{"Delete": ["x", "delete"]}`,
+			check: func(t *testing.T, km KeyMap) {
+				if keys := km.Delete.Keys(); !slices.Equal(keys, []string{"x", "delete"}) {
+					t.Errorf("Delete keys = %q", keys)
+				}
+				if help := km.Delete.Help().Key; help != "x/delete" {
+					t.Errorf("Delete help = %q", help)
+				}
+			},
+		},
+		{
+			name: "single key",
+			file: `{"Quit": "ctrl+q"}`,
+			check: func(t *testing.T, km KeyMap) {
+				if keys := km.Quit.Keys(); !slices.Equal(keys, []string{"ctrl+q"}) {
+					t.Errorf("Quit keys = %q", keys)
+				}
+			},
+		},
+		{
+			name: "unbind",
+			file: `{"Move": []}`,
+			check: func(t *testing.T, km KeyMap) {
+				if km.Move.Enabled() {
+					t.Error("Move is still enabled")
+				}
+			},
+		},
+		{
+			name: "free a key for another action",
+			file: `{"Delete": "m", "Move": "M"}`,
+			check: func(t *testing.T, km KeyMap) {
+				if km.Delete.Keys()[0] != "m" || km.Move.Keys()[0] != "M" {
+					t.Errorf("Delete %q, Move %q", km.Delete.Keys(), km.Move.Keys())
+				}
+			},
+		},
+		{name: "conflict", file: `{"Delete": "a"}`, wantErr: `key "a" is bound to both`},
+		{name: "unknown action", file: `{"Explode": "x"}`, wantErr: `unknown action "Explode"`},
+		{name: "nav is not rebindable", file: `{"Nav": "x"}`, wantErr: `unknown action "Nav"`},
+		{name: "empty key", file: `{"Delete": [""]}`, wantErr: "Delete: empty key"},
+		{name: "wrong type", file: `{"Delete": 1}`, wantErr: "expected a key or a list of keys"},
+		{name: "invalid json", file: `{`, wantErr: "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			km, err := LoadKeyMap(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadKeyMap() error = %v, want %q", err, tt.wantErr)
+				}
+				if keys := km.Delete.Keys(); !slices.Equal(keys, DefaultKeyMap().Delete.Keys()) {
+					t.Errorf("defaults not returned on error: Delete keys = %q", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, km)
+		})
+	}
+}
+
+func TestLoadKeyMapMissingFile(t *testing.T) {
+	if _, err := LoadKeyMap(filepath.Join(t.TempDir(), "keys.json")); err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+}
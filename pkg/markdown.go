@@ -0,0 +1,239 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Markdown checklists. Each context becomes a heading, nested contexts one
+// level deeper, and each task a GitHub-style checklist item:
+//
+//	# Work
+//
+//	- [ ] Ship the release · **high** · due 2026-10-20 · `deploy`
+//	  Notes go on indented lines below the item.
+//
+// Exports end each item with an HTML comment holding the task's UID, which
+// rendered Markdown hides, so a re-import updates the tasks it came from. On
+// import, checklist items nested under another item become tasks in a
+// sub-context named after their parent.
+
+const markdownSeparator = " · "
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	markdownItem    = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] (.*)$`)
+	markdownDue     = regexp.MustCompile(`^due (\d{4}-\d{2}-\d{2})$`)
+	markdownUID     = regexp.MustCompile(`\s*<!-- uid:(\S+) -->$`)
+)
+
+// FormatMarkdownItem renders a task as a checklist item, notes included, and
+// its UID if withUID is set.
+func (m *Model) FormatMarkdownItem(task Task, withUID bool) string {
+	check := " "
+	if task.Checked {
+		check = "x"
+	}
+	parts := []string{task.Task}
+	if task.Priority != "" {
+		parts = append(parts, "**"+task.Priority+"**")
+	}
+	if task.DueDate != "" {
+		parts = append(parts, "due "+task.DueDate)
+	}
+	if i := m.StatusIndex(task); i > 0 && i < len(m.Statuses)-1 {
+		parts = append(parts, "status "+task.Status)
+	}
+	if len(task.Tags) > 0 {
+		tags := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			tags[i] = "`" + tag + "`"
+		}
+		parts = append(parts, strings.Join(tags, " "))
+	}
+
+	item := fmt.Sprintf("- [%s] %s", check, strings.Join(parts, markdownSeparator))
+	if withUID && task.UID != "" {
+		item += " <!-- uid:" + task.UID + " -->"
+	}
+	if task.Notes != "" {
+		for _, line := range strings.Split(task.Notes, "\n") {
+			item += "\n" + strings.TrimRight("  "+line, " ")
+		}
+	}
+	return item
+}
+
+// WriteMarkdown writes the context root and its sub-contexts, or every
+// context if root is empty, as Markdown, with task UIDs if withUIDs is set.
+func (m *Model) WriteMarkdown(w io.Writer, root string, withUIDs bool) error {
+	contexts := slices.DeleteFunc(slices.Clone(m.Contexts), func(c string) bool {
+		return root != "" && c != root && !isSubContext(c, root)
+	})
+	rootDepth := 0
+	if root != "" {
+		rootDepth = contextDepth(root)
+	}
+
+	var b strings.Builder
+	for _, context := range treeOrder(contexts, func(string) bool { return true }) {
+		level := min(6, contextDepth(context)-rootDepth+1)
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s %s\n", strings.Repeat("#", level), contextLeaf(context))
+		tasks := slices.DeleteFunc(slices.Clone(m.Tasks), func(t Task) bool { return t.Context != context })
+		if len(tasks) > 0 {
+			b.WriteString("\n")
+		}
+		for _, task := range tasks {
+			b.WriteString(m.FormatMarkdownItem(task, withUIDs) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MarkdownContext renders the current context and its sub-contexts for
+// pasting elsewhere, so without UIDs.
+func (m *Model) MarkdownContext() string {
+	var b strings.Builder
+	m.WriteMarkdown(&b, m.CurrentContext, false)
+	return b.String()
+}
+
+// parseMarkdownItem reads the text after a checklist item's checkbox.
+func parseMarkdownItem(text string) (Task, error) {
+	var task Task
+	if match := markdownUID.FindStringSubmatch(text); match != nil {
+		task.UID = match[1]
+		text = text[:len(text)-len(match[0])]
+	}
+	parts := strings.Split(text, markdownSeparator)
+	task.Task = strings.TrimSpace(parts[0])
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch {
+		case slices.Contains([]string{"**high**", "**medium**", "**low**"}, part):
+			task.Priority = strings.Trim(part, "*")
+		case markdownDue.MatchString(part):
+			due := markdownDue.FindStringSubmatch(part)[1]
+			if _, err := time.Parse(time.DateOnly, due); err != nil {
+				return Task{}, fmt.Errorf("invalid due date %q", due)
+			}
+			task.DueDate = due
+		case strings.HasPrefix(part, "status "):
+			task.Status = strings.TrimPrefix(part, "status ")
+		case strings.HasPrefix(part, "`") && strings.HasSuffix(part, "`"):
+			for _, tag := range strings.Split(strings.Trim(part, "`"), "` `") {
+				task.Tags = append(task.Tags, tag)
+			}
+		default:
+			// Not one of ours: part of the text.
+			task.Task += markdownSeparator + part
+		}
+	}
+	if task.Task == "" {
+		return Task{}, fmt.Errorf("no task text")
+	}
+	return task, nil
+}
+
+// ImportMarkdown reads checklist items grouped under headings.
+func ImportMarkdown(r io.Reader) (ImportResult, error) {
+	var result ImportResult
+	var headings []string // heading text by level
+	type openItem struct {
+		indent  int
+		task    int // index into result.Tasks
+		context string
+	}
+	var items []openItem
+	pendingBlank := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t")
+
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			level := len(match[1])
+			headings = headings[:min(level-1, len(headings))]
+			for len(headings) < level-1 {
+				headings = append(headings, "")
+			}
+			headings = append(headings, cleanContextName(match[2]))
+			context := cleanContextName(strings.Join(headings, contextSeparator))
+			if context != "" && !slices.Contains(result.Contexts, context) {
+				result.Contexts = append(result.Contexts, context)
+			}
+			items = nil
+			continue
+		}
+
+		if match := markdownItem.FindStringSubmatch(line); match != nil {
+			indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+			for len(items) > 0 && items[len(items)-1].indent >= indent {
+				items = items[:len(items)-1]
+			}
+			context := cleanContextName(strings.Join(headings, contextSeparator))
+			if len(items) > 0 {
+				parent := items[len(items)-1]
+				context = parent.context + contextSeparator + cleanContextName(result.Tasks[parent.task].Task)
+				context = cleanContextName(context)
+			}
+			task, err := parseMarkdownItem(match[3])
+			if err != nil {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: %v", n, err))
+				continue
+			}
+			task.Checked = match[2] != " "
+			task.Context = context
+			result.Tasks = append(result.Tasks, task)
+			items = append(items, openItem{indent: indent, task: len(result.Tasks) - 1, context: context})
+			pendingBlank = 0
+			continue
+		}
+
+		// Indented text below an item is its notes.
+		if line == "" {
+			pendingBlank++
+			continue
+		}
+		if len(items) > 0 {
+			item := items[len(items)-1]
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent > item.indent {
+				task := &result.Tasks[item.task]
+				if task.Notes != "" {
+					task.Notes += strings.Repeat("\n", pendingBlank+1)
+				}
+				task.Notes += line[min(indent, item.indent+2):]
+				pendingBlank = 0
+				continue
+			}
+		}
+		items = nil
+		pendingBlank = 0
+	}
+	return result, scanner.Err()
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "md",
+		Extensions:  []string{".md", ".markdown"},
+		Description: "Markdown checklist",
+		Export: func(w io.Writer, m *Model) error {
+			return m.WriteMarkdown(w, "", true)
+		},
+		Import: func(r io.Reader, _ ImportOptions) (ImportResult, error) {
+			return ImportMarkdown(r)
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportMarkdown(t *testing.T) {
+	input := `# Work
+
+- [ ] Ship the release · **high** · due 2026-10-20 · ` + "`deploy` `q3`" + ` <!-- uid:u-1 -->
+  Notes go here.
+
+  After a blank line.
+- [x] Done already
+  - [ ] Nested item
+* [X] Star bullet · status Review
+
+## Client A
+
+- [ ] Sub-context task · not a field
+
+Plain paragraph.
+    - [ ] Item after a paragraph
+- [ ] Bad date · due 2026-13-40
+`
+	result, err := ImportMarkdown(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Task: "Ship the release", Priority: "high", DueDate: "2026-10-20", Tags: []string{"deploy", "q3"}, Context: "Work",
+			Notes: "Notes go here.\n\nAfter a blank line.", UID: "u-1"},
+		{Task: "Done already", Checked: true, Context: "Work"},
+		{Task: "Nested item", Context: "Work/Done already"},
+		{Task: "Star bullet", Checked: true, Status: "Review", Context: "Work"},
+		{Task: "Sub-context task · not a field", Context: "Work/Client A"},
+		{Task: "Item after a paragraph", Context: "Work/Client A"},
+	}
+	if len(result.Tasks) != len(want) {
+		t.Fatalf("imported %d tasks, want %d: %+v", len(result.Tasks), len(want), result.Tasks)
+	}
+	for i := range want {
+		if !tasksEqual(result.Tasks[i], want[i]) {
+			t.Errorf("task %d\n got %+v\nwant %+v", i, result.Tasks[i], want[i])
+		}
+	}
+	if got := strings.Join(result.Contexts, "|"); got != "Work|Work/Client A" {
+		t.Errorf("contexts = %q", got)
+	}
+	if len(result.Skipped) != 1 || !strings.HasPrefix(result.Skipped[0], "line 17:") {
+		t.Errorf("skipped = %q", result.Skipped)
+	}
+}
+
+func TestMarkdownContextOmitsUIDs(t *testing.T) {
+	m := testModel()
+	out := m.MarkdownContext()
+	if strings.Contains(out, "uid:") {
+		t.Errorf("copied Markdown contains UIDs:\n%s", out)
+	}
+	if !strings.Contains(out, "# Work\n\n- [ ] Ship the release, v2; then rest · **high** · due 2026-10-20 · status In Progress · `deploy` `q3`\n  First line\n  second line\n") {
+		t.Errorf("unexpected Markdown:\n%s", out)
+	}
+}
@@ -0,0 +1,163 @@
+package todo
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Mouse support. Hit testing mirrors the layout produced by the matching
+// Render* functions.
+
+// mouseWheelStep is the number of lines scrolled per wheel notch.
+const mouseWheelStep = 3
+
+func (m Model) UpdateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.HelpVisible || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	switch m.ViewMode {
+	case NormalView:
+		m.mouseNormalView(msg)
+	case KanbanView:
+		m.mouseKanbanView(msg)
+	case RemoveTagView:
+		m.mouseRemoveTagView(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) mouseNormalView(msg tea.MouseMsg) {
+	if m.DetailVisible && m.WindowWidth < detailSplitMinWidth {
+		return
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.ScrollNormalBy(-mouseWheelStep)
+		return
+	case tea.MouseButtonWheelDown:
+		m.ScrollNormalBy(mouseWheelStep)
+		return
+	case tea.MouseButtonLeft:
+	default:
+		return
+	}
+
+	if m.DetailVisible && msg.X >= m.detailListWidth() {
+		return
+	}
+	if msg.Y == 0 {
+		_, tabs := m.contextTabBar()
+		x := msg.X - m.Theme.Base.GetPaddingLeft()
+		for _, tab := range tabs {
+			if x >= tab.Start && x < tab.End {
+				m.JumpToContext(slices.Index(m.VisibleContexts(), tab.Context))
+				m.ScrollToSelection()
+			}
+		}
+		return
+	}
+	row := msg.Y - len(m.normalHeaderLines())
+	tasks := m.GetFilteredTasks()
+	if row < 0 || row >= m.normalListHeight() || m.NormalScrollY+row >= len(tasks) {
+		return
+	}
+	m.SelectedIndex = m.NormalScrollY + row
+	if m.MovingMode {
+		return
+	}
+
+	task := tasks[m.SelectedIndex]
+	checkboxX := m.Theme.Base.GetPaddingLeft() + lipgloss.Width(m.priorityMarker(task)) + m.taskRowStyle(task).GetPaddingLeft()
+	if msg.X >= checkboxX && msg.X < checkboxX+3 {
+		m.SaveStateForUndo()
+		m.ToggleCurrentTask()
+		m.SaveConfig()
+	}
+}
+
+// ScrollNormalBy scrolls the normal list, dragging the selection along so it
+// stays inside the viewport.
+func (m *Model) ScrollNormalBy(delta int) {
+	total := len(m.GetFilteredTasks())
+	height := m.normalListHeight()
+	m.NormalScrollY = max(0, min(m.NormalScrollY+delta, total-height))
+	margin := min(scrollOff, (height-1)/2)
+	top := m.NormalScrollY + margin
+	if m.NormalScrollY == 0 {
+		top = 0
+	}
+	bottom := m.NormalScrollY + height - 1 - margin
+	if m.NormalScrollY+height >= total {
+		bottom = total - 1
+	}
+	m.SelectedIndex = max(top, min(m.SelectedIndex, bottom))
+}
+
+func (m *Model) mouseKanbanView(msg tea.MouseMsg) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelLeft,
+		msg.Button == tea.MouseButtonWheelUp && msg.Shift:
+		m.KanbanScrollX = max(0, m.KanbanScrollX-1)
+		return
+	case msg.Button == tea.MouseButtonWheelRight,
+		msg.Button == tea.MouseButtonWheelDown && msg.Shift:
+		m.KanbanScrollX = min(m.KanbanScrollX+1, max(0, len(m.kanbanColumns())-m.kanbanVisibleCols()))
+		return
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.KanbanScrollY = max(0, m.KanbanScrollY-mouseWheelStep)
+		return
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.KanbanScrollY += mouseWheelStep
+		return
+	case msg.Button != tea.MouseButtonLeft || m.MovingMode:
+		return
+	}
+
+	columns := m.kanbanColumns()
+	x := msg.X - m.Theme.Base.GetPaddingLeft()
+	line := msg.Y - lipgloss.Height(m.kanbanTitle()) + m.KanbanScrollY
+	if x < 0 || line < 0 {
+		return
+	}
+	col := m.KanbanScrollX + x/m.kanbanColumnStride()
+	if col >= len(columns) || col >= m.KanbanScrollX+m.kanbanVisibleCols() {
+		return
+	}
+
+	m.KanbanCol = col
+	top := kanbanHeaderLines
+	for row, task := range columns[col].Tasks {
+		height := lipgloss.Height(m.renderKanbanCard(task, false, false))
+		if line >= top && line < top+height {
+			m.KanbanRow = row
+			m.syncKanbanSelection()
+			// The bullet doubles as the card's checkbox.
+			if line == top && x%m.kanbanColumnStride() < 3 {
+				m.SaveStateForUndo()
+				m.ToggleCurrentTask()
+				m.SaveConfig()
+				m.refocusKanban()
+			}
+			return
+		}
+		top += height
+	}
+	// Clicking the header or empty space just focuses the column.
+	m.syncKanbanSelection()
+}
+
+func (m *Model) mouseRemoveTagView(msg tea.MouseMsg) {
+	if msg.Button != tea.MouseButtonLeft {
+		return
+	}
+	style := m.Theme.Input
+	// The tag list follows the prompt and a blank line inside the box.
+	row := msg.Y - style.GetMarginTop() - style.GetBorderTopSize() - style.GetPaddingTop() - 2
+	if row >= 0 && row < len(m.RemoveTagChecks) {
+		m.RemoveTagIndex = row
+		m.RemoveTagChecks[row] = !m.RemoveTagChecks[row]
+	}
+}
@@ -0,0 +1,241 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Org mode. Each context is a top-level heading and each task a TODO or DONE
+// heading below it:
+//
+//	* Work
+//	** TODO [#A] Ship the release :deploy:
+//	   DEADLINE: <2026-10-20 Tue>
+//	   :PROPERTIES:
+//	   :ID: 6f1c...
+//	   :END:
+//	   Notes, indented to the heading's text.
+//
+// The property drawer keeps the UID and any intermediate status, so an
+// exported file reads back into the same tasks.
+
+var (
+	orgHeading  = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgKeyword  = regexp.MustCompile(`^([A-Z]+)(?:\s+(.*))?$`)
+	orgPriority = regexp.MustCompile(`^\[#([A-Z])\]\s*(.*)$`)
+	orgTags     = regexp.MustCompile(`^(.*?)\s+(:[^\s:]+(?::[^\s:]+)*:)$`)
+	orgDeadline = regexp.MustCompile(`DEADLINE:\s*<([^>\s]+)[^>]*>`)
+	orgProperty = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+)
+
+var orgPriorities = map[string]string{"A": "high", "B": "medium", "C": "low"}
+
+// orgTag replaces the characters Org doesn't allow in tags with "_".
+func orgTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_@#%", r) {
+			return r
+		}
+		return '_'
+	}, tag)
+}
+
+// FormatOrgEntry renders a task as a second-level Org heading.
+func (m *Model) FormatOrgEntry(task Task) string {
+	const indent = "   "
+	heading := "** TODO "
+	if task.Checked {
+		heading = "** DONE "
+	}
+	for letter, p := range orgPriorities {
+		if p == task.Priority {
+			heading += "[#" + letter + "] "
+		}
+	}
+	heading += task.Task
+	if len(task.Tags) > 0 {
+		tags := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			tags[i] = orgTag(tag)
+		}
+		heading += " :" + strings.Join(tags, ":") + ":"
+	}
+
+	lines := []string{heading}
+	if due, err := time.Parse(time.DateOnly, task.DueDate); err == nil {
+		lines = append(lines, indent+"DEADLINE: <"+due.Format("2006-01-02 Mon")+">")
+	}
+	status := ""
+	if i := m.StatusIndex(task); i > 0 && i < len(m.Statuses)-1 {
+		status = task.Status
+	}
+	if task.UID != "" || status != "" {
+		lines = append(lines, indent+":PROPERTIES:")
+		if task.UID != "" {
+			lines = append(lines, indent+":ID: "+task.UID)
+		}
+		if status != "" {
+			lines = append(lines, indent+":STATUS: "+status)
+		}
+		lines = append(lines, indent+":END:")
+	}
+	if task.Notes != "" {
+		for _, line := range strings.Split(task.Notes, "\n") {
+			lines = append(lines, strings.TrimRight(indent+line, " "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WriteOrg writes every context as a top-level heading with its tasks.
+func (m *Model) WriteOrg(w io.Writer) error {
+	var b strings.Builder
+	for _, context := range m.Contexts {
+		fmt.Fprintf(&b, "* %s\n", context)
+		for _, task := range m.Tasks {
+			if task.Context == context {
+				b.WriteString(m.FormatOrgEntry(task) + "\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// parseOrgHeading reads a task heading's text: keyword, priority, title and
+// tags.
+func parseOrgHeading(text string) (Task, error) {
+	var task Task
+	if match := orgKeyword.FindStringSubmatch(text); match != nil {
+		switch match[1] {
+		case "DONE":
+			task.Checked = true
+			text = match[2]
+		case "TODO":
+			text = match[2]
+		}
+	}
+	if match := orgPriority.FindStringSubmatch(text); match != nil {
+		priority, ok := orgPriorities[match[1]]
+		if !ok {
+			priority = "low"
+		}
+		task.Priority = priority
+		text = match[2]
+	}
+	if match := orgTags.FindStringSubmatch(text); match != nil {
+		text = match[1]
+		task.Tags = strings.Split(strings.Trim(match[2], ":"), ":")
+	}
+	task.Task = strings.TrimSpace(text)
+	if task.Task == "" {
+		return Task{}, fmt.Errorf("no task text")
+	}
+	return task, nil
+}
+
+// ImportOrg reads tasks from an Org file: top-level headings are contexts and
+// deeper headings tasks, with their deadline, properties and body.
+func ImportOrg(r io.Reader) (ImportResult, error) {
+	var result ImportResult
+	var (
+		context string
+		task    *Task
+		drawer  bool
+		indent  int // indentation of the current task's body
+		blanks  int
+	)
+	finish := func() {
+		if task != nil {
+			task.Notes = strings.TrimRight(task.Notes, "\n")
+			result.Tasks = append(result.Tasks, *task)
+			task = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t")
+
+		if match := orgHeading.FindStringSubmatch(line); match != nil {
+			finish()
+			if len(match[1]) == 1 {
+				if m := orgTags.FindStringSubmatch(match[2]); m != nil {
+					match[2] = m[1]
+				}
+				context = cleanContextName(match[2])
+				if context != "" {
+					result.Contexts = append(result.Contexts, context)
+				}
+				continue
+			}
+			parsed, err := parseOrgHeading(match[2])
+			if err != nil {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: %v", n, err))
+				continue
+			}
+			parsed.Context = context
+			task, drawer, indent, blanks = &parsed, false, len(match[1])+1, 0
+			continue
+		}
+		if task == nil {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case drawer && trimmed == ":END:":
+			drawer = false
+		case drawer:
+			if match := orgProperty.FindStringSubmatch(trimmed); match != nil {
+				switch strings.ToUpper(match[1]) {
+				case "ID":
+					task.UID = match[2]
+				case "STATUS":
+					task.Status = match[2]
+				}
+			}
+		case trimmed == ":PROPERTIES:" && task.Notes == "":
+			drawer = true
+		case orgDeadline.MatchString(trimmed) && task.Notes == "":
+			date := orgDeadline.FindStringSubmatch(trimmed)[1]
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: invalid deadline %q", n, date))
+				task = nil
+				continue
+			}
+			task.DueDate = date
+		case trimmed == "":
+			blanks++
+		default:
+			if task.Notes != "" {
+				task.Notes += strings.Repeat("\n", blanks+1)
+			}
+			lead := len(line) - len(strings.TrimLeft(line, " "))
+			task.Notes += line[min(lead, indent):]
+			blanks = 0
+		}
+	}
+	finish()
+	return result, scanner.Err()
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "org",
+		Extensions:  []string{".org"},
+		Description: "Org mode outline",
+		Export: func(w io.Writer, m *Model) error {
+			return m.WriteOrg(w)
+		},
+		Import: func(r io.Reader, _ ImportOptions) (ImportResult, error) {
+			return ImportOrg(r)
+		},
+	})
+}
@@ -0,0 +1,103 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormatOrgEntry(t *testing.T) {
+	m := testModel()
+	want := "** TODO [#A] Ship the release, v2; then rest :deploy:q3:\n" +
+		"   DEADLINE: <2026-10-20 Tue>\n" +
+		"   :PROPERTIES:\n" +
+		"   :ID: " + m.Tasks[0].UID + "\n" +
+		"   :STATUS: In Progress\n" +
+		"   :END:\n" +
+		"   First line\n" +
+		"   second line"
+	if got := m.FormatOrgEntry(m.Tasks[0]); got != want {
+		t.Errorf("FormatOrgEntry()\n got %q\nwant %q", got, want)
+	}
+	task := Task{Task: "Done", Checked: true, Tags: []string{"tag with space", "a:b"}}
+	if got := m.FormatOrgEntry(task); got != "** DONE Done :tag_with_space:a_b:" {
+		t.Errorf("FormatOrgEntry() = %q", got)
+	}
+}
+
+func TestImportOrg(t *testing.T) {
+	input := `#+TITLE: Tasks
+Text before any heading.
+* Work :project:
+** TODO [#B] Write report :q3:draft:
+   DEADLINE: <2026-10-20 Tue 10:00>
+   :PROPERTIES:
+   :ID: u-1
+   :Status: Review
+   :END:
+   First paragraph.
+
+   Second paragraph.
+     Indented further.
+*** DONE [#D] Sub-heading task
+** Plain heading
+   :PROPERTIES: in the notes
+** TODO Bad deadline
+   DEADLINE: <2026-02-30 Mon>
+** TODO
+* Home
+** DONE Buy milk
+`
+	result, err := ImportOrg(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Task: "Write report", Context: "Work", Priority: "medium", Tags: []string{"q3", "draft"}, DueDate: "2026-10-20",
+			UID: "u-1", Status: "Review", Notes: "First paragraph.\n\nSecond paragraph.\n  Indented further."},
+		{Task: "Sub-heading task", Context: "Work", Checked: true, Priority: "low"},
+		{Task: "Plain heading", Context: "Work", Notes: ":PROPERTIES: in the notes"},
+		{Task: "Buy milk", Context: "Home", Checked: true},
+	}
+	if len(result.Tasks) != len(want) {
+		t.Fatalf("imported %+v, want %+v", result.Tasks, want)
+	}
+	for i := range want {
+		if !tasksEqual(result.Tasks[i], want[i]) {
+			t.Errorf("task %d\n got %+v\nwant %+v", i, result.Tasks[i], want[i])
+		}
+	}
+	if got := strings.Join(result.Contexts, "|"); got != "Work|Home" {
+		t.Errorf("contexts = %q", got)
+	}
+	wantSkipped := []string{`line 18: invalid deadline "2026-02-30"`, "line 19: no task text"}
+	if strings.Join(result.Skipped, "|") != strings.Join(wantSkipped, "|") {
+		t.Errorf("skipped %q, want %q", result.Skipped, wantSkipped)
+	}
+}
+
+func TestOrgRoundTrip(t *testing.T) {
+	m := testModel()
+	m.Tasks[1].Notes = "Whole milk\n\n  two litres"
+	var buf bytes.Buffer
+	if err := m.WriteOrg(&buf); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportOrg(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != len(m.Tasks) || len(result.Skipped) != 0 {
+		t.Fatalf("imported %+v, skipped %q", result.Tasks, result.Skipped)
+	}
+	for i, task := range result.Tasks {
+		want := m.Tasks[i]
+		want.ID = 0
+		if want.Checked || m.StatusIndex(want) == 0 {
+			want.Status = ""
+		}
+		if !tasksEqual(task, want) {
+			t.Errorf("task %d\n got %+v\nwant %+v", i, task, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package todo
+
+import (
+	_ "embed"
+	"errors"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTML status reports. The report is a single file rendered from an
+// html/template; the built-in template inlines its styles so the page has no
+// external assets, and a report.html.tmpl in the config directory replaces it.
+// Templates receive a Report.
+
+//go:embed report.html.tmpl
+var defaultReportTemplate string
+
+// Report is the data passed to report templates.
+type Report struct {
+	Title     string // the note file's name
+	Generated string // time the report was made, "2006-01-02 15:04"
+	Counts    ReportCounts
+	Contexts  []ReportContext // in tree order
+	Tags      []ReportTag     // alphabetically
+}
+
+// ReportCounts summarise a set of tasks.
+type ReportCounts struct {
+	Total, Completed, Overdue, DueThisWeek int
+	Percent                                int // completed, rounded down
+}
+
+// ReportContext is a context's section of the report.
+type ReportContext struct {
+	Name        string // full name, e.g. "Work/ClientA"
+	Leaf        string // last segment of the name
+	Depth       int
+	Description string
+	Color       string // hex colour, if the context has one
+	Archived    bool
+	Anchor      string
+	Counts      ReportCounts
+	Tasks       []ReportTask
+}
+
+// ReportTask is a task as shown in the report.
+type ReportTask struct {
+	Task
+	Anchor   string
+	Due      string // DueDate in the configured date format
+	Overdue  bool
+	Progress string // Status if it is neither the first nor the last
+}
+
+// ReportTag lists the tasks carrying a tag.
+type ReportTag struct {
+	Name  string
+	Tasks []ReportTask
+}
+
+// DefaultReportTemplate returns the built-in report template.
+func DefaultReportTemplate() string {
+	return defaultReportTemplate
+}
+
+// ReportTemplatePath returns the path of the user's report template.
+func ReportTemplatePath() string {
+	return filepath.Join(ConfigDir(), "report.html.tmpl")
+}
+
+// countTasks summarises tasks as of now.
+func (m *Model) countTasks(tasks []Task, now time.Time) ReportCounts {
+	var c ReportCounts
+	today := now.Format(time.DateOnly)
+	weekStart, weekEnd := m.Settings.WeekBounds(now)
+	for _, task := range tasks {
+		c.Total++
+		if task.Checked {
+			c.Completed++
+			continue
+		}
+		if task.DueDate == "" {
+			continue
+		}
+		if task.DueDate < today {
+			c.Overdue++
+		}
+		if due, err := time.ParseInLocation(time.DateOnly, task.DueDate, now.Location()); err == nil && !due.Before(weekStart) && due.Before(weekEnd) {
+			c.DueThisWeek++
+		}
+	}
+	if c.Total > 0 {
+		c.Percent = c.Completed * 100 / c.Total
+	}
+	return c
+}
+
+// reportAnchor makes an HTML id from a name.
+func reportAnchor(prefix, name string) string {
+	return prefix + "-" + strings.Map(func(r rune) rune {
+		if r == ' ' || r == '/' {
+			return '-'
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// NewReport collects the report data as of now.
+func (m *Model) NewReport(now time.Time) Report {
+	today := now.Format(time.DateOnly)
+	r := Report{
+		Title:     strings.TrimSuffix(filepath.Base(m.ConfigFilePath), filepath.Ext(m.ConfigFilePath)),
+		Generated: now.Format("2006-01-02 15:04"),
+		Counts:    m.countTasks(m.Tasks, now),
+	}
+
+	tags := make(map[string][]ReportTask)
+	for _, context := range treeOrder(m.Contexts, func(string) bool { return true }) {
+		meta := m.ContextMeta[context]
+		section := ReportContext{
+			Name:        context,
+			Leaf:        contextLeaf(context),
+			Depth:       contextDepth(context),
+			Description: meta.Description,
+			Archived:    m.contextArchived(context),
+			Anchor:      reportAnchor("context", context),
+		}
+		if hexColor.MatchString(meta.Color) {
+			section.Color = meta.Color
+		}
+		var tasks []Task
+		for _, task := range m.Tasks {
+			if task.Context != context {
+				continue
+			}
+			tasks = append(tasks, task)
+			rt := ReportTask{
+				Task:    task,
+				Anchor:  reportAnchor("task", strconv.Itoa(task.ID)),
+				Overdue: !task.Checked && task.DueDate != "" && task.DueDate < today,
+			}
+			if task.DueDate != "" {
+				rt.Due = m.Settings.FormatDate(task.DueDate)
+			}
+			if i := m.StatusIndex(task); i > 0 && i < len(m.Statuses)-1 {
+				rt.Progress = task.Status
+			}
+			section.Tasks = append(section.Tasks, rt)
+			for _, tag := range task.Tags {
+				tags[tag] = append(tags[tag], rt)
+			}
+		}
+		section.Counts = m.countTasks(tasks, now)
+		r.Contexts = append(r.Contexts, section)
+	}
+
+	for name, tasks := range tags {
+		r.Tags = append(r.Tags, ReportTag{Name: name, Tasks: tasks})
+	}
+	slices.SortFunc(r.Tags, func(a, b ReportTag) int { return strings.Compare(a.Name, b.Name) })
+	return r
+}
+
+// LoadReportTemplate parses the template at path, or the built-in template
+// if path is empty and the user has no template of their own.
+func LoadReportTemplate(path string) (*template.Template, error) {
+	text := defaultReportTemplate
+	if path == "" {
+		path = ReportTemplatePath()
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			path = ""
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	funcs := template.FuncMap{
+		"indent": func(depth int) int { return depth * 24 },
+	}
+	name := path
+	if name == "" {
+		name = "report"
+	}
+	return template.New(name).Funcs(funcs).Parse(text)
+}
+
+// WriteHTMLReport renders the report with tmpl.
+func (m *Model) WriteHTMLReport(w io.Writer, tmpl *template.Template) error {
+	return tmpl.Execute(w, m.NewReport(time.Now()))
+}
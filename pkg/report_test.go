@@ -0,0 +1,84 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportModel returns testModel with a nested context and a task whose
+// text needs escaping.
+func reportModel() *Model {
+	m := testModel()
+	m.ConfigFilePath = "/notes/team.json"
+	m.Contexts = append(m.Contexts, "Work/Docs")
+	m.ContextMeta = map[string]ContextMeta{
+		"Work/Docs": {Description: "Manuals & guides", Color: "#336699"},
+	}
+	m.Tasks = append(m.Tasks, Task{ID: 4, Task: "Fix <script> & tidy", Context: "Work/Docs", DueDate: "2026-10-01", Tags: []string{"q3"}})
+	m.normalizeStatuses()
+	return m
+}
+
+func TestNewReport(t *testing.T) {
+	m := reportModel()
+	r := m.NewReport(time.Date(2026, 10, 19, 9, 30, 0, 0, time.Local))
+
+	if r.Title != "team" || r.Generated != "2026-10-19 09:30" {
+		t.Errorf("title %q, generated %q", r.Title, r.Generated)
+	}
+	if want := (ReportCounts{Total: 4, Completed: 1, Overdue: 1, DueThisWeek: 1, Percent: 25}); r.Counts != want {
+		t.Errorf("counts = %+v, want %+v", r.Counts, want)
+	}
+	var names []string
+	for _, c := range r.Contexts {
+		names = append(names, c.Name)
+	}
+	if want := "Work Work/Docs Home"; strings.Join(names, " ") != want {
+		t.Errorf("contexts = %q, want %s", names, want)
+	}
+	docs := r.Contexts[1]
+	if docs.Leaf != "Docs" || docs.Depth != 1 || docs.Color != "#336699" || docs.Anchor != "context-work-docs" {
+		t.Errorf("Work/Docs section = %+v", docs)
+	}
+	if len(docs.Tasks) != 1 || !docs.Tasks[0].Overdue || docs.Counts.Overdue != 1 {
+		t.Errorf("Work/Docs tasks = %+v, counts %+v", docs.Tasks, docs.Counts)
+	}
+	if work := r.Contexts[0]; len(work.Tasks) != 1 || work.Tasks[0].Progress != "In Progress" {
+		t.Errorf("Work tasks = %+v", work.Tasks)
+	}
+	if len(r.Tags) != 2 || r.Tags[0].Name != "deploy" || r.Tags[1].Name != "q3" || len(r.Tags[1].Tasks) != 2 {
+		t.Errorf("tags = %+v", r.Tags)
+	}
+}
+
+func TestWriteHTMLReportDefaultTemplate(t *testing.T) {
+	isolateHome(t)
+	tmpl, err := LoadReportTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := reportModel().WriteHTMLReport(&out, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	html := out.String()
+	for _, want := range []string{
+		"<title>team – status report</title>",
+		`<section class="context" id="context-work-docs" style="margin-left: 24px; border-color: #336699">`,
+		"<h2>Docs</h2>",
+		"Manuals &amp; guides",
+		"Fix &lt;script&gt; &amp; tidy",
+		`<span class="status">In Progress</span>`,
+		`<a href="#task-4">Fix &lt;script&gt; &amp; tidy</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("task text is not escaped")
+	}
+}
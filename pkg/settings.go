@@ -0,0 +1,326 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are the application's behaviour knobs, stored in settings.json in
+// the config directory, separate from the task data.
+type Settings struct {
+	DataFile          string   `json:"data_file"`           // note file used when none is given; empty for the default location
+	MaxHistory        int      `json:"max_history"`         // undo steps kept
+	CharLimit         int      `json:"char_limit"`          // maximum length of a task title, 0 for no limit
+	KanbanColumnWidth int      `json:"kanban_column_width"` // width of a kanban column in cells
+	DefaultView       string   `json:"default_view"`        // normal, kanban or stats
+	DateFormat        string   `json:"date_format"`         // Go time layout used to display due dates
+	WeekStart         string   `json:"week_start"`          // monday or sunday
+	ConfirmDelete     bool     `json:"confirm_delete"`      // ask before deleting a task
+	DefaultContext    string   `json:"default_context"`     // context selected when a note file is opened, created if missing
+	Lists             []string `json:"lists"`               // note files offered by the file switcher
+	Mouse             bool     `json:"mouse"`               // enable mouse input; disable to keep terminal text selection
+	CSVTagSeparator   string   `json:"csv_tag_separator"`   // separator between tags in CSV exports and imports
+	HookTimeout       int      `json:"hook_timeout"`        // seconds a hook may run before the change is refused
+	Webhooks          []string `json:"webhooks"`            // URLs that receive a POST for every task change
+}
+
+// DefaultSettings returns the settings used when no settings file exists.
+func DefaultSettings() Settings {
+	return Settings{
+		MaxHistory:        50,
+		CharLimit:         200,
+		KanbanColumnWidth: 35,
+		DefaultView:       "normal",
+		DateFormat:        time.DateOnly,
+		WeekStart:         "monday",
+		ConfirmDelete:     true,
+		Mouse:             true,
+		CSVTagSeparator:   ";",
+		HookTimeout:       5,
+	}
+}
+
+// SettingsFilePath returns the path of the settings file.
+func SettingsFilePath() string {
+	return filepath.Join(ConfigDir(), "settings.json")
+}
+
+// ReadSettings decodes the settings file on top of the defaults without
+// validating the result. A missing file yields the defaults.
+func ReadSettings(path string) (Settings, error) {
+	s := DefaultSettings()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// LoadSettings reads and validates the settings file.
+func LoadSettings(path string) (Settings, error) {
+	s, err := ReadSettings(path)
+	if err != nil {
+		return s, err
+	}
+	if err := s.Validate(); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSettings writes the settings file, creating its directory if needed.
+func SaveSettings(path string, s Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// settingChecks validates individual settings by key.
+var settingChecks = map[string]func(Settings) error{
+	"max_history": func(s Settings) error {
+		if s.MaxHistory < 1 {
+			return fmt.Errorf("max_history must be at least 1, got %d", s.MaxHistory)
+		}
+		return nil
+	},
+	"char_limit": func(s Settings) error {
+		if s.CharLimit < 0 {
+			return fmt.Errorf("char_limit cannot be negative, got %d", s.CharLimit)
+		}
+		return nil
+	},
+	"kanban_column_width": func(s Settings) error {
+		if s.KanbanColumnWidth < 10 {
+			return fmt.Errorf("kanban_column_width must be at least 10, got %d", s.KanbanColumnWidth)
+		}
+		return nil
+	},
+	"default_view": func(s Settings) error {
+		if !slices.Contains([]string{"normal", "kanban", "stats"}, s.DefaultView) {
+			return fmt.Errorf("default_view must be normal, kanban or stats, got %q", s.DefaultView)
+		}
+		return nil
+	},
+	"date_format": func(s Settings) error {
+		if !validDateLayout(s.DateFormat) {
+			return fmt.Errorf("date_format %q is not a Go date layout such as 2006-01-02 or 02 Jan 2006", s.DateFormat)
+		}
+		return nil
+	},
+	"week_start": func(s Settings) error {
+		if !slices.Contains([]string{"monday", "sunday"}, s.WeekStart) {
+			return fmt.Errorf("week_start must be monday or sunday, got %q", s.WeekStart)
+		}
+		return nil
+	},
+	"hook_timeout": func(s Settings) error {
+		if s.HookTimeout < 1 {
+			return fmt.Errorf("hook_timeout must be at least 1, got %d", s.HookTimeout)
+		}
+		return nil
+	},
+	"webhooks": func(s Settings) error {
+		for _, webhook := range s.Webhooks {
+			u, err := url.Parse(webhook)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("webhooks: %q is not an http or https URL", webhook)
+			}
+		}
+		return nil
+	},
+	"csv_tag_separator": func(s Settings) error {
+		if s.CSVTagSeparator == "" {
+			return errors.New("csv_tag_separator cannot be empty")
+		}
+		return nil
+	},
+}
+
+// Validate reports every invalid setting.
+func (s Settings) Validate() error {
+	var errs []error
+	for _, name := range SettingKeys() {
+		if check, ok := settingChecks[name]; ok {
+			errs = append(errs, check(s))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validDateLayout checks that layout round-trips a date.
+func validDateLayout(layout string) bool {
+	ref := time.Date(2006, time.November, 23, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, ref.Format(layout))
+	return err == nil && parsed.Equal(ref)
+}
+
+// SettingKeys lists the setting names in file order.
+func SettingKeys() []string {
+	t := reflect.TypeOf(Settings{})
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i] = settingKey(t.Field(i))
+	}
+	return keys
+}
+
+func settingKey(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("json"), ",")[0]
+}
+
+func (s *Settings) field(name string) (reflect.Value, error) {
+	v := reflect.ValueOf(s).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if settingKey(v.Type().Field(i)) == name {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown setting %q (known: %s)", name, strings.Join(SettingKeys(), ", "))
+}
+
+// Get returns the value of a setting as text.
+func (s Settings) Get(name string) (string, error) {
+	f, err := s.field(name)
+	if err != nil {
+		return "", err
+	}
+	if list, ok := f.Interface().([]string); ok {
+		return strings.Join(list, ","), nil
+	}
+	return fmt.Sprint(f.Interface()), nil
+}
+
+// Set parses value into the named setting and validates it.
+func (s *Settings) Set(name, value string) error {
+	f, err := s.field(name)
+	if err != nil {
+		return err
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", name, value)
+		}
+		f.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", name, value)
+		}
+		f.SetBool(b)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		f.Set(reflect.ValueOf(list))
+	}
+	if check, ok := settingChecks[name]; ok {
+		return check(*s)
+	}
+	return nil
+}
+
+// FormatDate renders a YYYY-MM-DD date with the configured date format.
+func (s Settings) FormatDate(date string) string {
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil || s.DateFormat == "" {
+		return date
+	}
+	return t.Format(s.DateFormat)
+}
+
+// WeekBounds returns the start of the week containing t and the start of the
+// following week, honouring WeekStart.
+func (s Settings) WeekBounds(t time.Time) (time.Time, time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	first := time.Monday
+	if s.WeekStart == "sunday" {
+		first = time.Sunday
+	}
+	offset := (int(day.Weekday()) - int(first) + 7) % 7
+	start := day.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// ParseDueDate turns user input into a YYYY-MM-DD date relative to now. It
+// accepts ISO dates, dates in the configured format, "today", "tomorrow",
+// weekday names (the next such day after today), "eow" for the last day of
+// the week, "nw" for the start of next week, and offsets such as "+3d" or
+// "+2w".
+func (s Settings) ParseDueDate(input string, now time.Time) (string, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	_, nextWeek := s.WeekBounds(now)
+
+	switch input {
+	case "today":
+		return today.Format(time.DateOnly), nil
+	case "tomorrow", "tom":
+		return today.AddDate(0, 0, 1).Format(time.DateOnly), nil
+	case "eow":
+		return nextWeek.AddDate(0, 0, -1).Format(time.DateOnly), nil
+	case "nw":
+		return nextWeek.Format(time.DateOnly), nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if input == name || input == name[:3] {
+			offset := (int(day)-int(today.Weekday())+6)%7 + 1
+			return today.AddDate(0, 0, offset).Format(time.DateOnly), nil
+		}
+	}
+
+	if len(input) > 2 && input[0] == '+' {
+		n, err := strconv.Atoi(input[1 : len(input)-1])
+		if err == nil {
+			switch input[len(input)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n).Format(time.DateOnly), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n).Format(time.DateOnly), nil
+			case 'm':
+				return today.AddDate(0, n, 0).Format(time.DateOnly), nil
+			}
+		}
+	}
+
+	for _, layout := range []string{time.DateOnly, s.DateFormat} {
+		if t, err := time.ParseInLocation(layout, input, now.Location()); layout != "" && err == nil {
+			if t.Year() == 0 {
+				t = t.AddDate(today.Year(), 0, 0)
+			}
+			return t.Format(time.DateOnly), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q: use YYYY-MM-DD, today, tomorrow, a weekday, eow, nw or +N[dwm]", input)
+}
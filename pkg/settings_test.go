@@ -0,0 +1,170 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultSettingsValidate(t *testing.T) {
+	if err := DefaultSettings().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSettingsSet(t *testing.T) {
+	tests := []struct {
+		name, value string
+		wantErr     string // substring of the error, empty for none
+		want        string // Get after a successful Set
+	}{
+		{name: "max_history", value: "10", want: "10"},
+		{name: "max_history", value: "0", wantErr: "at least 1"},
+		{name: "max_history", value: "many", wantErr: "must be a number"},
+		{name: "char_limit", value: "-1", wantErr: "cannot be negative"},
+		{name: "kanban_column_width", value: "9", wantErr: "at least 10"},
+		{name: "default_view", value: "kanban", want: "kanban"},
+		{name: "default_view", value: "grid", wantErr: "normal, kanban or stats"},
+		{name: "date_format", value: "02 Jan 2006", want: "02 Jan 2006"},
+		{name: "date_format", value: "yyyy-mm-dd", wantErr: "not a Go date layout"},
+		{name: "week_start", value: "sunday", want: "sunday"},
+		{name: "week_start", value: "friday", wantErr: "monday or sunday"},
+		{name: "confirm_delete", value: "false", want: "false"},
+		{name: "confirm_delete", value: "maybe", wantErr: "true or false"},
+		{name: "lists", value: "a.json, ,b.json", want: "a.json,b.json"},
+		{name: "csv_tag_separator", value: "", wantErr: "cannot be empty"},
+		{name: "hook_timeout", value: "0", wantErr: "at least 1"},
+		{name: "webhooks", value: "https://example.com/hook", want: "https://example.com/hook"},
+		{name: "webhooks", value: "ftp://example.com", wantErr: "not an http or https URL"},
+		{name: "colour", value: "red", wantErr: `unknown setting "colour"`},
+	}
+	for _, tt := range tests {
+		s := DefaultSettings()
+		err := s.Set(tt.name, tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Set(%q, %q) error = %v, want %q", tt.name, tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%q, %q): %v", tt.name, tt.value, err)
+			continue
+		}
+		if got, _ := s.Get(tt.name); got != tt.want {
+			t.Errorf("Get(%q) = %q after Set(%q), want %q", tt.name, got, tt.value, tt.want)
+		}
+	}
+}
+
+func TestValidateReportsEverySetting(t *testing.T) {
+	s := DefaultSettings()
+	s.MaxHistory = 0
+	s.WeekStart = "friday"
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("Validate accepted invalid settings")
+	}
+	for _, want := range []string{"max_history", "week_start"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, missing %s", err, want)
+		}
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+
+	s, err := LoadSettings(path)
+	if err != nil || s.MaxHistory != DefaultSettings().MaxHistory {
+		t.Fatalf("missing file: %+v, %v", s, err)
+	}
+
+	os.WriteFile(path, []byte(`{"max_history": 7}`), 0644)
+	if s, err = LoadSettings(path); err != nil || s.MaxHistory != 7 || !s.ConfirmDelete {
+		t.Errorf("partial file: %+v, %v", s, err)
+	}
+
+	os.WriteFile(path, []byte(`{"max_histroy": 7}`), 0644)
+	if _, err = LoadSettings(path); err == nil || !strings.Contains(err.Error(), "max_histroy") {
+		t.Errorf("unknown field: %v", err)
+	}
+
+	os.WriteFile(path, []byte(`{"default_view": "grid"}`), 0644)
+	if _, err = LoadSettings(path); err == nil || !strings.Contains(err.Error(), "default_view") {
+		t.Errorf("invalid value: %v", err)
+	}
+
+	want := DefaultSettings()
+	want.Lists = []string{"a.json"}
+	if err := SaveSettings(filepath.Join(dir, "sub", "settings.json"), want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSettings(filepath.Join(dir, "sub", "settings.json"))
+	if err != nil || !slices.Equal(got.Lists, want.Lists) {
+		t.Errorf("round trip: %+v, %v", got, err)
+	}
+}
+
+func TestParseDueDate(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 15, 0, 0, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		input, want string
+		weekStart   string
+	}{
+		{input: "today", want: "2026-10-14"},
+		{input: "tomorrow", want: "2026-10-15"},
+		{input: "wed", want: "2026-10-21"},
+		{input: "friday", want: "2026-10-16"},
+		{input: "eow", want: "2026-10-18"},
+		{input: "eow", want: "2026-10-17", weekStart: "sunday"},
+		{input: "nw", want: "2026-10-19"},
+		{input: "+3d", want: "2026-10-17"},
+		{input: "+2w", want: "2026-10-28"},
+		{input: "+1m", want: "2026-11-14"},
+		{input: "2027-01-02", want: "2027-01-02"},
+		{input: "soon"},
+	}
+	for _, tt := range tests {
+		s := DefaultSettings()
+		if tt.weekStart != "" {
+			s.WeekStart = tt.weekStart
+		}
+		got, err := s.ParseDueDate(tt.input, now)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseDueDate(%q) = %q, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDueDate(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultContextSelectedOnOpen(t *testing.T) {
+	home := isolateHome(t)
+	path := filepath.Join(home, "note.json")
+	os.WriteFile(path, []byte(`{"contexts": ["Work", "Home"], "tasks": [], "next_id": 1}`), 0644)
+
+	s := DefaultSettings()
+	s.DefaultContext = "Home"
+	if m := Initialize(path, s); m.CurrentContext != "Home" {
+		t.Errorf("current context = %q, want Home", m.CurrentContext)
+	}
+
+	s.DefaultContext = "Errands"
+	m := Initialize(path, s)
+	if m.CurrentContext != "Errands" || !slices.Equal(m.Contexts, []string{"Work", "Home", "Errands"}) {
+		t.Errorf("current context %q, contexts %q", m.CurrentContext, m.Contexts)
+	}
+	m.AddTask("Post the letter")
+	if task := m.GetCurrentTask(); task.Task != "Post the letter" || task.Context != "Errands" {
+		t.Errorf("new task = %+v", task)
+	}
+}
@@ -0,0 +1,184 @@
+package todo
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Context tab bar shown at the top of the normal view. Tabs that don't fit
+// scroll horizontally, keeping the current context in view.
+
+const (
+	tabScrollLeft  = "‹ "
+	tabScrollRight = " ›"
+)
+
+// contextTab is the position of a rendered tab on the tab bar line.
+type contextTab struct {
+	Context    string
+	Start, End int
+}
+
+// tabBarWidth is the room available to the tabs, leaving space for the file
+// name. Zero means unlimited.
+func (m *Model) tabBarWidth() int {
+	if m.WindowWidth == 0 {
+		return 0
+	}
+	width := m.WindowWidth - m.Theme.Base.GetHorizontalPadding()
+	if m.DetailVisible {
+		width = m.detailListWidth() - m.Theme.Base.GetPaddingLeft()
+	}
+	return max(1, width-lipgloss.Width(m.fileLabel()))
+}
+
+func (m *Model) fileLabel() string {
+	return m.Theme.Muted.Render(" " + filepath.Base(m.ConfigFilePath))
+}
+
+// contextCounts returns the open and total number of tasks in a context and
+// whether any open task is overdue.
+func (m *Model) contextCounts(context string) (open, total int, overdue bool) {
+	today := time.Now().Format(time.DateOnly)
+	for _, task := range m.GetTasksForContext(context) {
+		total++
+		if task.Checked {
+			continue
+		}
+		open++
+		if task.DueDate != "" && task.DueDate < today {
+			overdue = true
+		}
+	}
+	return open, total, overdue
+}
+
+// renderContextTab renders the tab of context, the i-th visible one,
+// prefixed with the key that jumps to it.
+func (m *Model) renderContextTab(i int, context string) string {
+	open, total, overdue := m.contextCounts(context)
+	label := fmt.Sprintf("%s %d/%d", context, open, total)
+	if keys := m.KeyMap.JumpContext.Keys(); m.KeyMap.JumpContext.Enabled() && i < len(keys) {
+		label = keyDisplayName(keys[i]) + " " + label
+	}
+
+	color, colored := m.contextColor(context)
+	if context == m.CurrentContext {
+		if overdue {
+			label += " !"
+		}
+		style := m.Theme.Title
+		if colored {
+			style = style.Background(color)
+		}
+		return style.Render(label)
+	}
+	if overdue {
+		label += " " + m.Theme.Error.Render("!")
+	}
+	style := m.Theme.Tab
+	if colored {
+		style = style.Foreground(color)
+	}
+	if m.contextArchived(context) {
+		style = style.Faint(true)
+	}
+	return style.Render(label)
+}
+
+// tabSpan is the range of tabs shown when the tab bar starts at first.
+func tabSpan(widths []int, first, width int) int {
+	used := 0
+	last := first
+	for last < len(widths) && used+widths[last] <= width {
+		used += widths[last]
+		last++
+	}
+	return last
+}
+
+// scrollTabsToContext adjusts TabScrollX so the current context's tab is
+// visible.
+func (m *Model) scrollTabsToContext() {
+	width := m.tabBarWidth()
+	contexts := m.VisibleContexts()
+	active := slices.Index(contexts, m.CurrentContext)
+	if width == 0 || active < 0 {
+		m.TabScrollX = 0
+		return
+	}
+
+	widths := make([]int, len(contexts))
+	sum := 0
+	for i, context := range contexts {
+		widths[i] = lipgloss.Width(m.renderContextTab(i, context))
+		sum += widths[i]
+	}
+	if sum <= width {
+		m.TabScrollX = 0
+		return
+	}
+
+	room := width - lipgloss.Width(tabScrollLeft) - lipgloss.Width(tabScrollRight)
+	m.TabScrollX = min(m.TabScrollX, active)
+	for m.TabScrollX < active && tabSpan(widths, m.TabScrollX, room) <= active {
+		m.TabScrollX++
+	}
+}
+
+// contextTabBar renders the visible tabs and reports where each one landed,
+// relative to the start of the line.
+func (m *Model) contextTabBar() (string, []contextTab) {
+	width := m.tabBarWidth()
+	contexts := m.VisibleContexts()
+	rendered := make([]string, len(contexts))
+	widths := make([]int, len(contexts))
+	sum := 0
+	for i, context := range contexts {
+		rendered[i] = m.renderContextTab(i, context)
+		widths[i] = lipgloss.Width(rendered[i])
+		sum += widths[i]
+	}
+
+	first, last := 0, len(contexts)
+	clipped := width > 0 && sum > width
+	if clipped {
+		room := width - lipgloss.Width(tabScrollLeft) - lipgloss.Width(tabScrollRight)
+		first = min(m.TabScrollX, len(contexts))
+		last = max(tabSpan(widths, first, room), min(first+1, len(contexts)))
+	}
+
+	var b strings.Builder
+	var tabs []contextTab
+	x := 0
+	if clipped {
+		arrow := strings.Repeat(" ", lipgloss.Width(tabScrollLeft))
+		if first > 0 {
+			arrow = m.Theme.Muted.Render(tabScrollLeft)
+		}
+		b.WriteString(arrow)
+		x += lipgloss.Width(tabScrollLeft)
+	}
+	for i := first; i < last; i++ {
+		b.WriteString(rendered[i])
+		tabs = append(tabs, contextTab{Context: contexts[i], Start: x, End: x + widths[i]})
+		x += widths[i]
+	}
+	if clipped && last < len(contexts) {
+		b.WriteString(m.Theme.Muted.Render(tabScrollRight))
+	}
+	return b.String(), tabs
+}
+
+// JumpToContext selects the i-th visible context.
+func (m *Model) JumpToContext(i int) {
+	if contexts := m.VisibleContexts(); i >= 0 && i < len(contexts) {
+		m.CurrentContext = contexts[i]
+		m.SelectedIndex = 0
+	}
+}
@@ -1,7 +1,10 @@
 package todo
 
 import (
+	"crypto/rand"
+	"crypto/sha1"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"time"
@@ -9,6 +12,7 @@ import (
 
 // ShowInputDialog switches the view to the text input mode.
 func (m *Model) ShowInputDialog(mode InputMode, prompt string) {
+	m.ReturnView = m.ViewMode
 	m.ViewMode = InputView
 	m.InputMode = mode
 	m.InputPrompt = prompt
@@ -16,8 +20,22 @@ func (m *Model) ShowInputDialog(mode InputMode, prompt string) {
 	m.TextInput.Focus()
 }
 
+// ShowDeleteTaskDialog asks for confirmation before deleting the selected task.
+func (m *Model) ShowDeleteTaskDialog() {
+	m.ShowInputDialog(DeleteTaskConfirmInput, fmt.Sprintf("Delete task '%s'? (y/n):", m.GetCurrentTask().Task))
+}
+
+// ShowNotesDialog opens the multi-line editor on the selected task's notes.
+func (m *Model) ShowNotesDialog() {
+	m.ReturnView = m.ViewMode
+	m.ViewMode = NotesInputView
+	m.NotesInput.SetValue(m.GetCurrentTask().Notes)
+	m.NotesInput.Focus()
+}
+
 // ShowDateInputDialog prepares the three-part date input fields.
 func (m *Model) ShowDateInputDialog() {
+	m.ReturnView = m.ViewMode
 	m.ViewMode = DateInputView
 	m.DateInputIndex = 0
 
@@ -31,6 +49,16 @@ func (m *Model) ShowDateInputDialog() {
 	}
 }
 
+// CloseDialog returns from an input dialog to the view that opened it.
+func (m *Model) CloseDialog() {
+	m.ViewMode = m.ReturnView
+	if m.ViewMode == KanbanView {
+		m.refocusKanban()
+	} else {
+		m.ScrollToSelection()
+	}
+}
+
 // ShowRemoveTagDialog initiates the tag removal flow.
 func (m *Model) ShowRemoveTagDialog() {
 	task := m.GetCurrentTask()
@@ -39,6 +67,7 @@ func (m *Model) ShowRemoveTagDialog() {
 		return
 	}
 
+	m.ReturnView = m.ViewMode
 	m.ViewMode = RemoveTagView
 	m.RemoveTagIndex = 0
 	m.RemoveTagChecks = make([]bool, len(task.Tags))
@@ -48,10 +77,13 @@ func (m *Model) GetFilteredTasks() []Task {
 	return m.GetTasksForContext(m.CurrentContext)
 }
 
+// GetTasksForContext returns the tasks in context, including those of its
+// sub-contexts when it aggregates them.
 func (m *Model) GetTasksForContext(context string) []Task {
+	aggregate := m.ContextMeta[context].Aggregate
 	var filtered []Task
 	for _, task := range m.Tasks {
-		if task.Context == context {
+		if task.Context == context || aggregate && isSubContext(task.Context, context) {
 			filtered = append(filtered, task)
 		}
 	}
@@ -66,6 +98,20 @@ func (m *Model) GetCurrentTask() Task {
 	return tasks[m.SelectedIndex]
 }
 
+// SelectTask switches to the task's context and selects it.
+func (m *Model) SelectTask(id int) bool {
+	idx := m.findTaskIndexByID(id)
+	if idx == -1 {
+		return false
+	}
+	m.CurrentContext = m.Tasks[idx].Context
+	m.SelectedIndex = slices.IndexFunc(m.GetFilteredTasks(), func(t Task) bool {
+		return t.ID == id
+	})
+	m.ScrollToSelection()
+	return true
+}
+
 func (m *Model) MoveUp() {
 	tasks := m.GetFilteredTasks()
 	if len(tasks) > 0 {
@@ -80,6 +126,55 @@ func (m *Model) MoveDown() {
 	}
 }
 
+// scrollOff is the number of tasks kept visible above and below the selection.
+const scrollOff = 2
+
+// normalListHeight is the number of task lines that fit between the pinned
+// header and footer of the normal view.
+func (m *Model) normalListHeight() int {
+	height := m.normalAvailableLines()
+	if m.normalListOverflows() {
+		height-- // scroll indicator
+	}
+	return max(1, height)
+}
+
+func (m *Model) normalAvailableLines() int {
+	height := m.WindowHeight - len(m.normalHeaderLines())
+	if m.ErrorMessage != "" {
+		height -= 2
+	}
+	return height
+}
+
+func (m *Model) normalListOverflows() bool {
+	return m.WindowHeight > 0 && len(m.GetFilteredTasks()) > m.normalAvailableLines()
+}
+
+// ScrollToSelection adjusts NormalScrollY so the selected task stays visible
+// with a scrollOff margin, and scrolls the tab bar to the current context.
+func (m *Model) ScrollToSelection() {
+	height := m.normalListHeight()
+	total := len(m.GetFilteredTasks())
+	margin := min(scrollOff, (height-1)/2)
+	if m.SelectedIndex-margin < m.NormalScrollY {
+		m.NormalScrollY = m.SelectedIndex - margin
+	}
+	if m.SelectedIndex+margin >= m.NormalScrollY+height {
+		m.NormalScrollY = m.SelectedIndex + margin - height + 1
+	}
+	m.NormalScrollY = max(0, min(m.NormalScrollY, total-height))
+	m.scrollTabsToContext()
+}
+
+// MoveBy moves the selection delta tasks without wrapping around.
+func (m *Model) MoveBy(delta int) {
+	tasks := m.GetFilteredTasks()
+	if len(tasks) > 0 {
+		m.SelectedIndex = max(0, min(m.SelectedIndex+delta, len(tasks)-1))
+	}
+}
+
 func (m *Model) findTaskIndexByID(id int) int {
 	return slices.IndexFunc(m.Tasks, func(t Task) bool {
 		return t.ID == id
@@ -115,30 +210,36 @@ func (m *Model) MoveTaskDown() {
 }
 
 func (m *Model) NextContext() {
-	if len(m.Contexts) > 0 {
-		currentIdx := slices.Index(m.Contexts, m.CurrentContext)
+	contexts := m.VisibleContexts()
+	if len(contexts) > 0 {
+		currentIdx := slices.Index(contexts, m.CurrentContext)
 		if currentIdx == -1 {
 			currentIdx = 0
 		}
-		nextIdx := (currentIdx + 1) % len(m.Contexts)
-		m.CurrentContext = m.Contexts[nextIdx]
+		nextIdx := (currentIdx + 1) % len(contexts)
+		m.CurrentContext = contexts[nextIdx]
 		m.SelectedIndex = 0
 	}
 }
 
 func (m *Model) PreviousContext() {
-	if len(m.Contexts) > 0 {
-		currentIdx := slices.Index(m.Contexts, m.CurrentContext)
+	contexts := m.VisibleContexts()
+	if len(contexts) > 0 {
+		currentIdx := slices.Index(contexts, m.CurrentContext)
 		if currentIdx == -1 {
 			currentIdx = 0
 		}
-		prevIdx := (currentIdx - 1 + len(m.Contexts)) % len(m.Contexts)
-		m.CurrentContext = m.Contexts[prevIdx]
+		prevIdx := (currentIdx - 1 + len(contexts)) % len(contexts)
+		m.CurrentContext = contexts[prevIdx]
 		m.SelectedIndex = 0
 	}
 }
 
 func (m *Model) AddContext(contextName string) {
+	contextName = cleanContextName(contextName)
+	if contextName == "" {
+		return
+	}
 	if slices.Contains(m.Contexts, contextName) {
 		m.ErrorMessage = "Context already exists"
 		return
@@ -146,58 +247,108 @@ func (m *Model) AddContext(contextName string) {
 	m.Contexts = append(m.Contexts, contextName)
 	m.CurrentContext = contextName
 	m.SelectedIndex = 0
+	m.UpdateContexts()
 }
 
+// RenameContext renames the current context together with all of its
+// sub-contexts. Nothing is changed if any of the new names is taken.
 func (m *Model) RenameContext(newName string) {
-	if newName == m.CurrentContext {
+	newName = cleanContextName(newName)
+	oldName := m.CurrentContext
+	if newName == "" || newName == oldName {
 		return
 	}
-	if slices.Contains(m.Contexts, newName) {
-		m.ErrorMessage = "Context name already exists"
+	if isSubContext(newName, oldName) {
+		m.ErrorMessage = "Cannot move a context inside itself"
 		return
 	}
-	oldName := m.CurrentContext
-	if idx := slices.Index(m.Contexts, oldName); idx != -1 {
-		m.Contexts[idx] = newName
+	renamed := func(context string) (string, bool) {
+		if context == oldName || isSubContext(context, oldName) {
+			return newName + strings.TrimPrefix(context, oldName), true
+		}
+		return context, false
 	}
-	for i := range m.Tasks {
-		if m.Tasks[i].Context == oldName {
-			m.Tasks[i].Context = newName
+	for _, context := range m.Contexts {
+		if target, moved := renamed(context); moved && slices.Contains(m.Contexts, target) {
+			m.ErrorMessage = fmt.Sprintf("Context '%s' already exists", target)
+			return
 		}
 	}
+
+	for i, context := range m.Contexts {
+		m.Contexts[i], _ = renamed(context)
+	}
+	for i := range m.Tasks {
+		m.Tasks[i].Context, _ = renamed(m.Tasks[i].Context)
+	}
+	meta := make(map[string]ContextMeta, len(m.ContextMeta))
+	for context, cm := range m.ContextMeta {
+		context, _ = renamed(context)
+		meta[context] = cm
+	}
+	m.ContextMeta = meta
+	collapsed := make(map[string]bool, len(m.TreeCollapsed))
+	for context := range m.TreeCollapsed {
+		context, _ = renamed(context)
+		collapsed[context] = true
+	}
+	m.TreeCollapsed = collapsed
 	m.CurrentContext = newName
+	m.UpdateContexts()
+}
+
+// canDeleteCurrentContext reports whether deleting the current context and
+// its sub-contexts would leave another context behind.
+func (m *Model) canDeleteCurrentContext() bool {
+	return len(m.Contexts) > 1+len(m.subContexts(m.CurrentContext))
 }
 
+// DeleteContext deletes the current context, its sub-contexts and their
+// tasks.
 func (m *Model) DeleteContext() {
-	if len(m.Contexts) <= 1 {
+	if !m.canDeleteCurrentContext() {
 		m.ErrorMessage = "Cannot delete the only context"
 		return
 	}
+	deleted := func(context string) bool {
+		return context == m.CurrentContext || isSubContext(context, m.CurrentContext)
+	}
 	m.Tasks = slices.DeleteFunc(m.Tasks, func(t Task) bool {
-		return t.Context == m.CurrentContext
+		return deleted(t.Context)
 	})
-	if idx := slices.Index(m.Contexts, m.CurrentContext); idx != -1 {
-		m.Contexts = slices.Delete(m.Contexts, idx, idx+1)
+	m.Contexts = slices.DeleteFunc(m.Contexts, deleted)
+	for context := range m.ContextMeta {
+		if deleted(context) {
+			delete(m.ContextMeta, context)
+		}
 	}
 	if len(m.Contexts) > 0 {
 		m.CurrentContext = m.Contexts[0]
 		m.SelectedIndex = 0
 	}
+	m.ensureVisibleContext()
 }
 
+// UpdateContexts keeps the user's context order, appending contexts that
+// only appear on tasks in alphabetical order.
 func (m *Model) UpdateContexts() {
-	uniqueContexts := make(map[string]bool)
-	for _, task := range m.Tasks {
-		uniqueContexts[task.Context] = true
-	}
+	seen := make(map[string]bool)
+	contexts := make([]string, 0, len(m.Contexts))
 	for _, ctx := range m.Contexts {
-		uniqueContexts[ctx] = true
+		if !seen[ctx] {
+			seen[ctx] = true
+			contexts = append(contexts, ctx)
+		}
 	}
-	m.Contexts = make([]string, 0, len(uniqueContexts))
-	for context := range uniqueContexts {
-		m.Contexts = append(m.Contexts, context)
+	var added []string
+	for _, task := range m.Tasks {
+		if !seen[task.Context] {
+			seen[task.Context] = true
+			added = append(added, task.Context)
+		}
 	}
-	slices.Sort(m.Contexts)
+	slices.Sort(added)
+	m.Contexts = withAncestors(append(contexts, added...))
 	if m.CurrentContext == "" || !slices.Contains(m.Contexts, m.CurrentContext) {
 		if len(m.Contexts) > 0 {
 			m.CurrentContext = m.Contexts[0]
@@ -206,6 +357,72 @@ func (m *Model) UpdateContexts() {
 			m.Contexts = []string{"Work"}
 		}
 	}
+	m.ensureVisibleContext()
+}
+
+// DefaultStatuses is the workflow used by note files that don't define one.
+var DefaultStatuses = []string{"Todo", "In Progress", "Review", "Done"}
+
+// validateStatuses checks that a workflow has at least two distinct, non-empty
+// statuses, so that its first and last differ.
+func validateStatuses(statuses []string) error {
+	if len(statuses) < 2 {
+		return fmt.Errorf("a workflow needs at least two statuses, got %d", len(statuses))
+	}
+	for i, status := range statuses {
+		if strings.TrimSpace(status) == "" {
+			return fmt.Errorf("status %d is empty", i+1)
+		}
+		if slices.Contains(statuses[:i], status) {
+			return fmt.Errorf("status %q is listed twice", status)
+		}
+	}
+	return nil
+}
+
+// repairStatuses drops empty and repeated statuses, falling back to
+// DefaultStatuses if fewer than two are left.
+func repairStatuses(statuses []string) []string {
+	var repaired []string
+	for _, status := range statuses {
+		if status = strings.TrimSpace(status); status != "" && !slices.Contains(repaired, status) {
+			repaired = append(repaired, status)
+		}
+	}
+	if len(repaired) < 2 {
+		return slices.Clone(DefaultStatuses)
+	}
+	return repaired
+}
+
+// normalizeStatuses makes sure a valid workflow is defined and every task's
+// Status is part of it and agrees with Checked, which wins on conflict.
+func (m *Model) normalizeStatuses() {
+	if validateStatuses(m.Statuses) != nil {
+		m.Statuses = repairStatuses(m.Statuses)
+	}
+	final := m.Statuses[len(m.Statuses)-1]
+	for i := range m.Tasks {
+		task := &m.Tasks[i]
+		switch {
+		case task.Checked:
+			task.Status = final
+		case task.Status == final || !slices.Contains(m.Statuses, task.Status):
+			task.Status = m.Statuses[0]
+		}
+	}
+}
+
+// StatusIndex returns the position of the task's status in the workflow.
+func (m *Model) StatusIndex(task Task) int {
+	return max(0, slices.Index(m.Statuses, task.Status))
+}
+
+// setTaskStatus updates the status of m.Tasks[idx], deriving Checked from
+// whether it reached the final state.
+func (m *Model) setTaskStatus(idx int, status string) {
+	m.Tasks[idx].Status = status
+	m.Tasks[idx].Checked = status == m.Statuses[len(m.Statuses)-1]
 }
 
 func (m *Model) ToggleCurrentTask() {
@@ -215,7 +432,59 @@ func (m *Model) ToggleCurrentTask() {
 	}
 	targetID := tasks[m.SelectedIndex].ID
 	if idx := m.findTaskIndexByID(targetID); idx != -1 {
-		m.Tasks[idx].Checked = !m.Tasks[idx].Checked
+		if m.Tasks[idx].Checked {
+			m.setTaskStatus(idx, m.Statuses[0])
+		} else {
+			m.setTaskStatus(idx, m.Statuses[len(m.Statuses)-1])
+		}
+	}
+}
+
+// AdvanceCurrentTaskStatus moves the selected task delta steps along the
+// workflow, stopping at either end.
+func (m *Model) AdvanceCurrentTaskStatus(delta int) {
+	tasks := m.GetFilteredTasks()
+	if len(tasks) == 0 {
+		return
+	}
+	targetID := tasks[m.SelectedIndex].ID
+	idx := m.findTaskIndexByID(targetID)
+	if idx == -1 {
+		return
+	}
+	next := m.StatusIndex(m.Tasks[idx]) + delta
+	if next < 0 || next >= len(m.Statuses) {
+		return
+	}
+	m.setTaskStatus(idx, m.Statuses[next])
+}
+
+// newTaskUID returns a random (version 4) UUID.
+func newTaskUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// legacyTaskUID returns a name-based (version 5) UUID for a task saved
+// before UIDs existed, derived from the note file and the task's ID so that
+// it stays the same across loads until a save stores it.
+func legacyTaskUID(path string, id int) string {
+	sum := sha1.Sum(fmt.Appendf(nil, "srn-todo:%s#%d", path, id))
+	b := sum[:16]
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// assignMissingUIDs gives a UID to every task without one.
+func (m *Model) assignMissingUIDs() {
+	for i := range m.Tasks {
+		if m.Tasks[i].UID == "" {
+			m.Tasks[i].UID = legacyTaskUID(m.ConfigFilePath, m.Tasks[i].ID)
+		}
 	}
 }
 
@@ -225,6 +494,8 @@ func (m *Model) AddTask(taskText string) {
 		Task:    taskText,
 		Checked: false,
 		Context: m.CurrentContext,
+		Status:  m.Statuses[0],
+		UID:     newTaskUID(),
 	}
 	m.Tasks = append(m.Tasks, newTask)
 	m.NextID++
@@ -243,6 +514,17 @@ func (m *Model) EditCurrentTask(newText string) {
 	}
 }
 
+func (m *Model) SetNotesForCurrentTask(notes string) {
+	tasks := m.GetFilteredTasks()
+	if len(tasks) == 0 {
+		return
+	}
+	targetID := tasks[m.SelectedIndex].ID
+	if idx := m.findTaskIndexByID(targetID); idx != -1 {
+		m.Tasks[idx].Notes = strings.TrimRight(notes, "\n ")
+	}
+}
+
 func (m *Model) DeleteCurrentTask() {
 	tasks := m.GetFilteredTasks()
 	if len(tasks) == 0 {
@@ -335,20 +617,39 @@ func (m *Model) RemoveTagsFromCurrentTask() {
 }
 
 func (m *Model) SaveStateForUndo() {
-	stateCopy := slices.Clone(m.Tasks)
-	m.History = append(m.History, stateCopy)
+	m.History = append(m.History, UndoState{
+		Tasks:       slices.Clone(m.Tasks),
+		Contexts:    slices.Clone(m.Contexts),
+		ContextMeta: maps.Clone(m.ContextMeta),
+	})
 	if len(m.History) > m.MaxHistory {
 		m.History = m.History[1:]
 	}
 }
 
+// Undo restores the state saved before the last change. Contexts created
+// since are kept, after the restored ones.
 func (m *Model) Undo() {
 	if len(m.History) == 0 {
 		m.ErrorMessage = "Nothing to undo"
 		return
 	}
-	m.Tasks = m.History[len(m.History)-1]
+	state := m.History[len(m.History)-1]
 	m.History = m.History[:len(m.History)-1]
+
+	contexts, meta := state.Contexts, state.ContextMeta
+	for _, context := range m.Contexts {
+		if !slices.Contains(contexts, context) {
+			contexts = append(contexts, context)
+			if cm, ok := m.ContextMeta[context]; ok {
+				if meta == nil {
+					meta = make(map[string]ContextMeta)
+				}
+				meta[context] = cm
+			}
+		}
+	}
+	m.Tasks, m.Contexts, m.ContextMeta = state.Tasks, contexts, meta
 	m.UpdateContexts()
 	m.SelectedIndex = 0
 }
@@ -0,0 +1,289 @@
+package todo
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// isolateHome points the config, data and state directories at a fresh
+// temporary home and returns it.
+func isolateHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	return home
+}
+
+func TestValidateStatuses(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		valid    bool
+	}{
+		{[]string{"Todo", "Done"}, true},
+		{DefaultStatuses, true},
+		{nil, false},
+		{[]string{"Done"}, false},
+		{[]string{"Todo", " ", "Done"}, false},
+		{[]string{"Todo", "Doing", "Todo"}, false},
+	}
+	for _, tt := range tests {
+		if err := validateStatuses(tt.statuses); (err == nil) != tt.valid {
+			t.Errorf("validateStatuses(%q) = %v, want valid %v", tt.statuses, err, tt.valid)
+		}
+	}
+}
+
+func TestNormalizeStatusesRepairs(t *testing.T) {
+	tests := []struct {
+		statuses, want []string
+	}{
+		{nil, DefaultStatuses},
+		{[]string{"Done"}, DefaultStatuses},
+		{[]string{"Todo", "Todo"}, DefaultStatuses},
+		{[]string{"Todo", "Doing", "Todo", "", "Done"}, []string{"Todo", "Doing", "Done"}},
+	}
+	for _, tt := range tests {
+		m := Model{Statuses: slices.Clone(tt.statuses), Tasks: []Task{{ID: 1, Task: "a", Status: "Doing"}}}
+		m.normalizeStatuses()
+		if !slices.Equal(m.Statuses, tt.want) {
+			t.Errorf("normalizeStatuses(%q) = %q, want %q", tt.statuses, m.Statuses, tt.want)
+		}
+		if !slices.Contains(m.Statuses, m.Tasks[0].Status) {
+			t.Errorf("normalizeStatuses(%q) left task status %q", tt.statuses, m.Tasks[0].Status)
+		}
+	}
+}
+
+func TestToggleWithTwoStatuses(t *testing.T) {
+	m := Model{
+		Statuses:       []string{"Open", "Closed"},
+		Tasks:          []Task{{ID: 1, Task: "a", Context: "Work"}},
+		Contexts:       []string{"Work"},
+		CurrentContext: "Work",
+	}
+	m.normalizeStatuses()
+	m.ToggleCurrentTask()
+	if !m.Tasks[0].Checked || m.Tasks[0].Status != "Closed" {
+		t.Fatalf("after toggle: %+v", m.Tasks[0])
+	}
+	m.ToggleCurrentTask()
+	if m.Tasks[0].Checked || m.Tasks[0].Status != "Open" {
+		t.Fatalf("after second toggle: %+v", m.Tasks[0])
+	}
+}
+
+func TestRunStatuses(t *testing.T) {
+	home := isolateHome(t)
+	m := Model{
+		ConfigFilePath: filepath.Join(home, "note.json"),
+		Statuses:       slices.Clone(DefaultStatuses),
+		Tasks:          []Task{{ID: 1, Task: "a", Context: "Work", Status: "Review"}},
+		Contexts:       []string{"Work"},
+		CurrentContext: "Work",
+	}
+	if _, err := runStatuses(&m, "Open"); err == nil {
+		t.Error("runStatuses accepted a single status")
+	}
+	if _, err := runStatuses(&m, "Open, Open"); err == nil {
+		t.Error("runStatuses accepted a duplicate status")
+	}
+	if _, err := runStatuses(&m, "Open, Doing, Closed"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Open", "Doing", "Closed"}; !slices.Equal(m.Statuses, want) {
+		t.Errorf("statuses = %q, want %q", m.Statuses, want)
+	}
+	if m.Tasks[0].Status != "Open" {
+		t.Errorf("task with a dropped status has %q, want Open", m.Tasks[0].Status)
+	}
+}
+
+// contextModel returns a model with nested contexts and their metadata.
+func contextModel() *Model {
+	m := &Model{
+		Statuses:       slices.Clone(DefaultStatuses),
+		MaxHistory:     10,
+		Contexts:       []string{"Zeta", "Alpha", "Alpha/Sub", "Alpha/Sub/Deep", "Alphabet"},
+		CurrentContext: "Alpha",
+		ContextMeta: map[string]ContextMeta{
+			"Alpha":     {Color: "red"},
+			"Alpha/Sub": {Description: "Nested", Aggregate: true},
+			"Alphabet":  {Color: "blue"},
+		},
+		TreeCollapsed: map[string]bool{"Alpha/Sub": true},
+		Tasks: []Task{
+			{ID: 1, Task: "z", Context: "Zeta"},
+			{ID: 2, Task: "a", Context: "Alpha"},
+			{ID: 3, Task: "s", Context: "Alpha/Sub", Checked: true},
+			{ID: 4, Task: "d", Context: "Alpha/Sub/Deep"},
+			{ID: 5, Task: "b", Context: "Alphabet"},
+		},
+	}
+	m.normalizeStatuses()
+	return m
+}
+
+func TestUndoDeleteContext(t *testing.T) {
+	m := contextModel()
+	before := slices.Clone(m.Tasks)
+	m.SaveStateForUndo()
+	m.DeleteContext()
+	if want := []string{"Zeta", "Alphabet"}; !slices.Equal(m.Contexts, want) {
+		t.Fatalf("contexts after delete = %q, want %q", m.Contexts, want)
+	}
+	if len(m.Tasks) != 2 || len(m.ContextMeta) != 1 {
+		t.Fatalf("tasks %+v, meta %+v", m.Tasks, m.ContextMeta)
+	}
+	m.AddContext("New")
+
+	m.Undo()
+	if want := []string{"Zeta", "Alpha", "Alpha/Sub", "Alpha/Sub/Deep", "Alphabet", "New"}; !slices.Equal(m.Contexts, want) {
+		t.Errorf("contexts after undo = %q, want %q", m.Contexts, want)
+	}
+	if m.ContextMeta["Alpha"].Color != "red" || m.ContextMeta["Alpha/Sub"].Description != "Nested" || m.ContextMeta["Alphabet"].Color != "blue" {
+		t.Errorf("meta after undo = %+v", m.ContextMeta)
+	}
+	if !slices.EqualFunc(m.Tasks, before, tasksEqual) {
+		t.Errorf("tasks after undo = %+v", m.Tasks)
+	}
+}
+
+func TestRenameContextMovesSubtree(t *testing.T) {
+	m := contextModel()
+	m.RenameContext("Beta")
+	if want := []string{"Zeta", "Beta", "Beta/Sub", "Beta/Sub/Deep", "Alphabet"}; !slices.Equal(m.Contexts, want) {
+		t.Errorf("contexts = %q, want %q", m.Contexts, want)
+	}
+	if m.CurrentContext != "Beta" {
+		t.Errorf("current context = %q, want Beta", m.CurrentContext)
+	}
+	want := map[int]string{1: "Zeta", 2: "Beta", 3: "Beta/Sub", 4: "Beta/Sub/Deep", 5: "Alphabet"}
+	for _, task := range m.Tasks {
+		if task.Context != want[task.ID] {
+			t.Errorf("task %d in %q, want %q", task.ID, task.Context, want[task.ID])
+		}
+	}
+	if m.ContextMeta["Beta"].Color != "red" || m.ContextMeta["Beta/Sub"].Description != "Nested" || m.ContextMeta["Alphabet"].Color != "blue" {
+		t.Errorf("meta = %+v", m.ContextMeta)
+	}
+	if _, ok := m.ContextMeta["Alpha"]; ok {
+		t.Errorf("meta still has Alpha: %+v", m.ContextMeta)
+	}
+	if !m.TreeCollapsed["Beta/Sub"] || len(m.TreeCollapsed) != 1 {
+		t.Errorf("collapsed = %v, want only Beta/Sub", m.TreeCollapsed)
+	}
+}
+
+func TestRenameContextRefusesClash(t *testing.T) {
+	tests := []struct {
+		context, name, err string
+	}{
+		{"Alpha", "Zeta", "Context 'Zeta' already exists"},
+		{"Alpha/Sub", "Alpha", "Context 'Alpha' already exists"},
+		{"Zeta", "Alpha/Sub", "Context 'Alpha/Sub' already exists"},
+		{"Alpha", "Alpha/Other", "Cannot move a context inside itself"},
+	}
+	for _, tt := range tests {
+		m := contextModel()
+		m.CurrentContext = tt.context
+		before := contextModel()
+		m.RenameContext(tt.name)
+		if m.ErrorMessage != tt.err {
+			t.Errorf("rename %q to %q: error %q, want %q", tt.context, tt.name, m.ErrorMessage, tt.err)
+		}
+		if !slices.Equal(m.Contexts, before.Contexts) || !slices.EqualFunc(m.Tasks, before.Tasks, tasksEqual) ||
+			len(m.ContextMeta) != len(before.ContextMeta) || !m.TreeCollapsed["Alpha/Sub"] {
+			t.Errorf("rename %q to %q changed the model: %q %+v", tt.context, tt.name, m.Contexts, m.Tasks)
+		}
+	}
+}
+
+func TestWithAncestors(t *testing.T) {
+	tests := []struct {
+		in, want []string
+	}{
+		{nil, []string{}},
+		{[]string{"Work"}, []string{"Work"}},
+		{[]string{"Home", "Work/Project/Docs"}, []string{"Home", "Work", "Work/Project", "Work/Project/Docs"}},
+		{[]string{"Work/B", "Work", "Work/A"}, []string{"Work", "Work/B", "Work/A"}},
+	}
+	for _, tt := range tests {
+		if got := withAncestors(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("withAncestors(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAggregatedContextCounts(t *testing.T) {
+	m := contextModel()
+	tests := []struct {
+		context     string
+		open, total int
+	}{
+		{"Alpha", 1, 1},
+		{"Alpha/Sub", 1, 2},
+		{"Alpha/Sub/Deep", 1, 1},
+	}
+	for _, tt := range tests {
+		if open, total, _ := m.contextCounts(tt.context); open != tt.open || total != tt.total {
+			t.Errorf("counts of %q = %d/%d, want %d/%d", tt.context, open, total, tt.open, tt.total)
+		}
+	}
+	if !m.aggregatedIntoParent("Alpha/Sub/Deep") || m.aggregatedIntoParent("Alpha/Sub") {
+		t.Error("only Alpha/Sub/Deep should fold into its parent")
+	}
+
+	m.CurrentContext = "Alpha"
+	m.ToggleAggregateCurrentContext()
+	if open, total, _ := m.contextCounts("Alpha"); open != 2 || total != 3 {
+		t.Errorf("counts of aggregating Alpha = %d/%d, want 2/3", open, total)
+	}
+	if !m.aggregatedIntoParent("Alpha/Sub") || m.aggregatedIntoParent("Alphabet") {
+		t.Error("Alpha/Sub should fold into Alpha and Alphabet into nothing")
+	}
+	var titles []string
+	for _, column := range m.kanbanColumns() {
+		titles = append(titles, column.Title)
+	}
+	if want := []string{"Zeta", "Alpha", "Alphabet"}; !slices.Equal(titles, want) {
+		t.Errorf("kanban columns = %q, want %q", titles, want)
+	}
+
+	m.CurrentContext = "Alpha/Sub/Deep"
+	m.ToggleAggregateCurrentContext()
+	if m.ErrorMessage != "'Alpha/Sub/Deep' has no sub-contexts" {
+		t.Errorf("aggregating a leaf: error %q", m.ErrorMessage)
+	}
+}
+
+func TestDeleteContextSubtree(t *testing.T) {
+	m := contextModel()
+	m.CurrentContext = "Alpha/Sub"
+	m.DeleteContext()
+	if want := []string{"Zeta", "Alpha", "Alphabet"}; !slices.Equal(m.Contexts, want) {
+		t.Errorf("contexts = %q, want %q", m.Contexts, want)
+	}
+	var ids []int
+	for _, task := range m.Tasks {
+		ids = append(ids, task.ID)
+	}
+	if want := []int{1, 2, 5}; !slices.Equal(ids, want) {
+		t.Errorf("task IDs = %v, want %v", ids, want)
+	}
+	if _, ok := m.ContextMeta["Alpha/Sub"]; ok || m.ContextMeta["Alpha"].Color != "red" {
+		t.Errorf("meta = %+v", m.ContextMeta)
+	}
+	if m.CurrentContext != "Zeta" {
+		t.Errorf("current context = %q, want Zeta", m.CurrentContext)
+	}
+
+	m = &Model{Contexts: []string{"Work", "Work/A"}, CurrentContext: "Work"}
+	m.DeleteContext()
+	if m.ErrorMessage != "Cannot delete the only context" || len(m.Contexts) != 2 {
+		t.Errorf("deleting the only tree: error %q, contexts %q", m.ErrorMessage, m.Contexts)
+	}
+}
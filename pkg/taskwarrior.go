@@ -0,0 +1,142 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Taskwarrior's `task export` output, either a JSON array or one object per
+// line. Projects become contexts, with their dots as the "/" of sub-contexts,
+// annotations become notes and the task's uuid its UID, so exporting again
+// later and re-importing updates tasks rather than duplicating them.
+
+const taskwarriorDateLayout = "20060102T150405Z"
+
+var taskwarriorPriorities = map[string]string{"H": "high", "M": "medium", "L": "low"}
+
+type taskwarriorTask struct {
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+	Priority    string   `json:"priority"`
+	Due         string   `json:"due"`
+	Status      string   `json:"status"`
+	Annotations []struct {
+		Entry       string `json:"entry"`
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// taskwarriorDate converts a Taskwarrior UTC timestamp to a local date.
+func taskwarriorDate(value string) (string, error) {
+	t, err := time.Parse(taskwarriorDateLayout, value)
+	if err != nil {
+		return "", err
+	}
+	return t.Local().Format(time.DateOnly), nil
+}
+
+// task converts a Taskwarrior task.
+func (tw taskwarriorTask) task() (Task, error) {
+	task := Task{
+		Task:    strings.TrimSpace(tw.Description),
+		Context: cleanContextName(strings.ReplaceAll(tw.Project, ".", contextSeparator)),
+		Tags:    tw.Tags,
+		UID:     tw.UUID,
+	}
+	switch tw.Status {
+	case "pending", "waiting", "":
+	case "completed":
+		task.Checked = true
+	case "deleted":
+		return Task{}, errors.New("deleted")
+	case "recurring":
+		return Task{}, errors.New("recurrence template; its pending instances are imported")
+	default:
+		return Task{}, fmt.Errorf("unknown status %q", tw.Status)
+	}
+	if task.Task == "" {
+		return Task{}, errors.New("no description")
+	}
+	if tw.Priority != "" {
+		priority, ok := taskwarriorPriorities[tw.Priority]
+		if !ok {
+			return Task{}, fmt.Errorf("invalid priority %q", tw.Priority)
+		}
+		task.Priority = priority
+	}
+	if tw.Due != "" {
+		due, err := taskwarriorDate(tw.Due)
+		if err != nil {
+			return Task{}, fmt.Errorf("invalid due date %q", tw.Due)
+		}
+		task.DueDate = due
+	}
+	var notes []string
+	for _, a := range tw.Annotations {
+		if date, err := taskwarriorDate(a.Entry); err == nil {
+			notes = append(notes, date+" "+a.Description)
+		} else {
+			notes = append(notes, a.Description)
+		}
+	}
+	task.Notes = strings.Join(notes, "\n")
+	return task, nil
+}
+
+// ImportTaskwarrior reads the output of `task export`.
+func ImportTaskwarrior(r io.Reader) (ImportResult, error) {
+	var result ImportResult
+	dec := json.NewDecoder(r)
+	var tasks []taskwarriorTask
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return result, err
+		}
+		if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+			var list []taskwarriorTask
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return result, err
+			}
+			tasks = append(tasks, list...)
+			continue
+		}
+		var tw taskwarriorTask
+		if err := json.Unmarshal(raw, &tw); err != nil {
+			return result, err
+		}
+		tasks = append(tasks, tw)
+	}
+
+	for i, tw := range tasks {
+		task, err := tw.task()
+		if err != nil {
+			label := tw.Description
+			if label == "" {
+				label = tw.UUID
+			}
+			result.Skipped = append(result.Skipped, fmt.Sprintf("task %d (%s): %v", i+1, label, err))
+			continue
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	return result, nil
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "taskwarrior",
+		Description: "Taskwarrior `task export` JSON (import only)",
+		Import: func(r io.Reader, _ ImportOptions) (ImportResult, error) {
+			return ImportTaskwarrior(r)
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportTaskwarrior(t *testing.T) {
+	due := time.Date(2026, 10, 20, 12, 0, 0, 0, time.Local).UTC().Format(taskwarriorDateLayout)
+	entry := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local).UTC().Format(taskwarriorDateLayout)
+	objects := []string{
+		`{"uuid":"u-1","description":"Ship the release","project":"Work.Client A","tags":["deploy"],"priority":"H","due":"` + due + `","status":"pending","annotations":[{"entry":"` + entry + `","description":"ask Bob"},{"entry":"bad","description":"undated"}]}`,
+		`{"uuid":"u-2","description":"Done already","status":"completed","priority":"L"}`,
+		`{"uuid":"u-3","description":"Gone","status":"deleted"}`,
+		`{"uuid":"u-4","description":"Weekly","status":"recurring"}`,
+		`{"uuid":"u-5","status":"pending"}`,
+		`{"uuid":"u-6","description":"Bad priority","priority":"X"}`,
+	}
+	want := []Task{
+		{Task: "Ship the release", Context: "Work/Client A", Tags: []string{"deploy"}, Priority: "high", DueDate: "2026-10-20",
+			Notes: "2026-10-01 ask Bob\nundated", UID: "u-1"},
+		{Task: "Done already", Checked: true, Priority: "low", UID: "u-2"},
+	}
+	wantSkipped := []string{"task 3 (Gone): deleted", "task 4 (Weekly): recurrence", "task 5 (u-5): no description", "task 6 (Bad priority): invalid priority"}
+
+	inputs := map[string]string{
+		"array":        "[" + strings.Join(objects, ",\n") + "]\n",
+		"one per line": strings.Join(objects, "\n") + "\n",
+		"split arrays": "[" + strings.Join(objects[:2], ",") + "]\n[" + strings.Join(objects[2:], ",") + "]",
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			result, err := ImportTaskwarrior(strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Tasks) != len(want) {
+				t.Fatalf("imported %+v, want %+v", result.Tasks, want)
+			}
+			for i := range want {
+				if !tasksEqual(result.Tasks[i], want[i]) {
+					t.Errorf("task %d\n got %+v\nwant %+v", i, result.Tasks[i], want[i])
+				}
+			}
+			if len(result.Skipped) != len(wantSkipped) {
+				t.Fatalf("skipped %q, want %q", result.Skipped, wantSkipped)
+			}
+			for i, prefix := range wantSkipped {
+				if !strings.HasPrefix(result.Skipped[i], prefix) {
+					t.Errorf("skipped[%d] = %q, want prefix %q", i, result.Skipped[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestImportTaskwarriorInvalidJSON(t *testing.T) {
+	for _, input := range []string{`[{"description": "x"}`, `{"description": 1}`, "not json"} {
+		if _, err := ImportTaskwarrior(strings.NewReader(input)); err == nil {
+			t.Errorf("ImportTaskwarrior(%q) did not fail", input)
+		}
+	}
+}
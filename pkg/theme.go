@@ -0,0 +1,238 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Themes. The styles used by the views are built from a Palette; users can
+// pick a built-in palette and override individual colours in theme.json:
+//
+//	{
+//	  "base": "light",
+//	  "colors": {"title_bg": "#8839EF"}
+//	}
+//
+// "base" is "dark", "light" or "auto" (the default), which picks one from the
+// terminal background. NO_COLOR switches to a monochrome theme that relies on
+// bold, underline and reverse video instead.
+
+// Palette holds the colours a theme is built from.
+type Palette struct {
+	TitleFg     string `json:"title_fg"`
+	TitleBg     string `json:"title_bg"`
+	SelectedFg  string `json:"selected_fg"`
+	HighlightBg string `json:"highlight_bg"`
+	Completed   string `json:"completed"`
+	High        string `json:"high"`
+	Medium      string `json:"medium"`
+	Low         string `json:"low"`
+	Context     string `json:"context"`
+	Error       string `json:"error"`
+	Muted       string `json:"muted"`
+	Border      string `json:"border"`
+}
+
+// Theme holds every style used by the views.
+type Theme struct {
+	Name string
+
+	Base           lipgloss.Style
+	Title          lipgloss.Style
+	Task           lipgloss.Style
+	SelectedTask   lipgloss.Style
+	CompletedTask  lipgloss.Style
+	Highlight      lipgloss.Style // layered onto the style of the selected row
+	HighPriority   lipgloss.Style
+	MediumPriority lipgloss.Style
+	LowPriority    lipgloss.Style
+	Context        lipgloss.Style
+	Tab            lipgloss.Style // inactive context tabs; the active one uses Title
+	Muted          lipgloss.Style
+	Error          lipgloss.Style
+	Input          lipgloss.Style
+	Pane           lipgloss.Style
+}
+
+// Palettes lists the built-in colour palettes by name.
+var Palettes = map[string]Palette{
+	"dark": {
+		TitleFg:     "#FFFDF5",
+		TitleBg:     "#25A065",
+		SelectedFg:  "#EE6FF8",
+		HighlightBg: "#313244",
+		Completed:   "#A6E3A1",
+		High:        "#F38BA8",
+		Medium:      "#FAB387",
+		Low:         "#F9E2AF",
+		Context:     "#89B4FA",
+		Error:       "#F38BA8",
+		Muted:       "#6C7086",
+		Border:      "#89B4FA",
+	},
+	"light": {
+		TitleFg:     "#EFF1F5",
+		TitleBg:     "#40A02B",
+		SelectedFg:  "#8839EF",
+		HighlightBg: "#CCD0DA",
+		Completed:   "#40A02B",
+		High:        "#D20F39",
+		Medium:      "#FE640B",
+		Low:         "#DF8E1D",
+		Context:     "#1E66F5",
+		Error:       "#D20F39",
+		Muted:       "#8C8FA1",
+		Border:      "#1E66F5",
+	},
+}
+
+// NewTheme builds the view styles from a palette.
+func NewTheme(name string, p Palette) Theme {
+	color := func(c string) lipgloss.Color { return lipgloss.Color(c) }
+	return Theme{
+		Name: name,
+		Base: lipgloss.NewStyle().
+			PaddingLeft(1).
+			PaddingRight(1),
+		Title: lipgloss.NewStyle().
+			Foreground(color(p.TitleFg)).
+			Background(color(p.TitleBg)).
+			Padding(0, 1).
+			Bold(true),
+		Task: lipgloss.NewStyle().
+			PaddingLeft(2),
+		SelectedTask: lipgloss.NewStyle().
+			Foreground(color(p.SelectedFg)).
+			Background(color(p.HighlightBg)).
+			PaddingLeft(2),
+		CompletedTask: lipgloss.NewStyle().
+			Foreground(color(p.Completed)).
+			Strikethrough(true),
+		Highlight: lipgloss.NewStyle().
+			Background(color(p.HighlightBg)),
+		HighPriority: lipgloss.NewStyle().
+			Foreground(color(p.High)),
+		MediumPriority: lipgloss.NewStyle().
+			Foreground(color(p.Medium)),
+		LowPriority: lipgloss.NewStyle().
+			Foreground(color(p.Low)),
+		Context: lipgloss.NewStyle().
+			Foreground(color(p.Context)).
+			Bold(true),
+		Tab: lipgloss.NewStyle().
+			Foreground(color(p.Context)).
+			Padding(0, 1),
+		Muted: lipgloss.NewStyle().
+			Foreground(color(p.Muted)),
+		Error: lipgloss.NewStyle().
+			Foreground(color(p.Error)).
+			Bold(true),
+		Input: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			Padding(1).
+			Margin(1),
+		Pane: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(color(p.Border)),
+	}
+}
+
+// MonochromeTheme is used when NO_COLOR is set.
+func MonochromeTheme() Theme {
+	t := NewTheme("none", Palette{})
+	t.Title = lipgloss.NewStyle().Padding(0, 1).Bold(true).Reverse(true)
+	t.SelectedTask = lipgloss.NewStyle().PaddingLeft(2).Reverse(true)
+	t.CompletedTask = lipgloss.NewStyle().Strikethrough(true)
+	t.Highlight = lipgloss.NewStyle().Reverse(true)
+	t.HighPriority = lipgloss.NewStyle().Bold(true)
+	t.MediumPriority = lipgloss.NewStyle().Bold(true)
+	t.LowPriority = lipgloss.NewStyle()
+	t.Context = lipgloss.NewStyle().Bold(true).Underline(true)
+	t.Tab = lipgloss.NewStyle().Padding(0, 1)
+	t.Muted = lipgloss.NewStyle().Faint(true)
+	t.Error = lipgloss.NewStyle().Bold(true).Underline(true)
+	t.Pane = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
+	return t
+}
+
+// ThemeFilePath returns the path of the user theme file.
+func ThemeFilePath() string {
+	return filepath.Join(ConfigDir(), "theme.json")
+}
+
+// LoadTheme picks the theme to use: monochrome under NO_COLOR, otherwise the
+// base palette from the theme file (or the terminal background) with the
+// file's colour overrides applied. A missing file is not an error.
+func LoadTheme(path string) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return MonochromeTheme(), nil
+	}
+
+	var file struct {
+		Base   string            `json:"base"`
+		Colors map[string]string `json:"colors"`
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return autoTheme(), err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return autoTheme(), fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	name := file.Base
+	if name == "" || name == "auto" {
+		name = autoThemeName()
+	}
+	palette, ok := Palettes[name]
+	if !ok {
+		return autoTheme(), fmt.Errorf("%s: unknown base theme %q", path, file.Base)
+	}
+	if err := palette.override(file.Colors); err != nil {
+		return autoTheme(), fmt.Errorf("%s: %w", path, err)
+	}
+	return NewTheme(name, palette), nil
+}
+
+func autoThemeName() string {
+	if lipgloss.HasDarkBackground() {
+		return "dark"
+	}
+	return "light"
+}
+
+func autoTheme() Theme {
+	name := autoThemeName()
+	return NewTheme(name, Palettes[name])
+}
+
+// override replaces palette colours by their json name.
+func (p *Palette) override(colors map[string]string) error {
+	v := reflect.ValueOf(p).Elem()
+	var errs []error
+	for name, value := range colors {
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0] == name {
+				v.Field(i).SetString(value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("unknown colour %q", name))
+		} else if value == "" {
+			errs = append(errs, fmt.Errorf("colour %q is empty", name))
+		}
+	}
+	return errors.Join(errs...)
+}
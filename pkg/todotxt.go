@@ -0,0 +1,228 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// todo.txt format (http://todotxt.org). Priorities (A)/(B)/(C) map to
+// high/medium/low, @context to the task's context, +project and key:value to
+// tags and due: to the due date. Fields todo.txt has no syntax for are
+// written as extension keys (pri: on completed tasks, status:, notes: and
+// uid:, so a re-import updates the tasks it came from), and values are
+// percent-encoded where needed so exports read back unchanged.
+
+var (
+	todotxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todotxtDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todotxtKeyValue = regexp.MustCompile(`^([A-Za-z0-9_-]+):([^/\s].*)$`)
+)
+
+var todotxtPriorities = map[string]string{"A": "high", "B": "medium", "C": "low"}
+
+// todotxtKeys are the extension keys with a meaning of their own; tags that
+// look like them are written as +projects instead.
+var todotxtKeys = []string{"due", "pri", "status", "notes", "uid"}
+
+// todotxtPriorityValue maps a priority letter to a Task priority; letters
+// below C count as low.
+func todotxtPriorityValue(letter string) (string, bool) {
+	if len(letter) != 1 || letter[0] < 'A' || letter[0] > 'Z' {
+		return "", false
+	}
+	if p, ok := todotxtPriorities[letter]; ok {
+		return p, true
+	}
+	return "low", true
+}
+
+func todotxtPriorityLetter(priority string) string {
+	for letter, p := range todotxtPriorities {
+		if p == priority {
+			return letter
+		}
+	}
+	return ""
+}
+
+// todotxtEscape percent-encodes the characters that would split a value.
+func todotxtEscape(s string) string {
+	return strings.NewReplacer("%", "%25", " ", "%20", "\t", "%09", "\n", "%0A", "\r", "%0D").Replace(s)
+}
+
+// todotxtUnescape decodes a percent-encoded value, leaving text that isn't
+// validly encoded as it is.
+func todotxtUnescape(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
+
+// todotxtSpecial reports whether a word of task text would be read back as
+// something other than text.
+func todotxtSpecial(word string, first bool) bool {
+	switch {
+	case len(word) > 1 && (word[0] == '@' || word[0] == '+'):
+		return true
+	case todotxtKeyValue.MatchString(word):
+		return true
+	case first && (word == "x" || todotxtPriority.MatchString(word) || todotxtDate.MatchString(word)):
+		return true
+	}
+	return false
+}
+
+// todotxtText escapes the words of a task's text.
+func todotxtText(text string) string {
+	words := strings.Split(text, " ")
+	for i, word := range words {
+		word = strings.ReplaceAll(word, "%", "%25")
+		if todotxtSpecial(word, i == 0) {
+			word = fmt.Sprintf("%%%02X", word[0]) + word[1:]
+		}
+		words[i] = word
+	}
+	return strings.Join(words, " ")
+}
+
+// FormatTodoTxtLine renders a task as a todo.txt line.
+func (m *Model) FormatTodoTxtLine(task Task) string {
+	var parts []string
+	letter := todotxtPriorityLetter(task.Priority)
+	if task.Checked {
+		parts = append(parts, "x")
+	} else if letter != "" {
+		parts = append(parts, "("+letter+")")
+	}
+	parts = append(parts, todotxtText(task.Task))
+	if task.Context != "" {
+		parts = append(parts, "@"+todotxtEscape(task.Context))
+	}
+	for _, tag := range task.Tags {
+		if key, _, _ := strings.Cut(tag, ":"); todotxtKeyValue.MatchString(tag) && !slices.Contains(todotxtKeys, key) {
+			parts = append(parts, todotxtEscape(tag))
+		} else {
+			parts = append(parts, "+"+todotxtEscape(tag))
+		}
+	}
+	if task.DueDate != "" {
+		parts = append(parts, "due:"+task.DueDate)
+	}
+	if task.Checked && letter != "" {
+		parts = append(parts, "pri:"+letter)
+	}
+	if i := m.StatusIndex(task); i > 0 && i < len(m.Statuses)-1 {
+		parts = append(parts, "status:"+todotxtEscape(task.Status))
+	}
+	if task.Notes != "" {
+		parts = append(parts, "notes:"+todotxtEscape(task.Notes))
+	}
+	if task.UID != "" {
+		parts = append(parts, "uid:"+todotxtEscape(task.UID))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseTodoTxtLine reads a todo.txt line into a task.
+func ParseTodoTxtLine(line string) (Task, error) {
+	var task Task
+	words := strings.Split(strings.TrimSpace(line), " ")
+	i := 0
+	if words[i] == "x" {
+		task.Checked = true
+		i++
+	} else if match := todotxtPriority.FindStringSubmatch(words[i]); match != nil {
+		task.Priority, _ = todotxtPriorityValue(match[1])
+		i++
+	}
+	// Completion and creation dates; the Task has nowhere to keep them.
+	for n := 0; n < 2 && i < len(words) && todotxtDate.MatchString(words[i]); n++ {
+		i++
+	}
+
+	var text []string
+	for _, word := range words[i:] {
+		switch {
+		case len(word) > 1 && word[0] == '@':
+			if task.Context == "" {
+				task.Context = todotxtUnescape(word[1:])
+			} else {
+				task.Tags = append(task.Tags, todotxtUnescape(word))
+			}
+		case len(word) > 1 && word[0] == '+':
+			task.Tags = append(task.Tags, todotxtUnescape(word[1:]))
+		case todotxtKeyValue.MatchString(word):
+			key, value, _ := strings.Cut(word, ":")
+			value = todotxtUnescape(value)
+			switch key {
+			case "due":
+				if _, err := time.Parse(time.DateOnly, value); err != nil {
+					return Task{}, fmt.Errorf("invalid due date %q", value)
+				}
+				task.DueDate = value
+			case "pri":
+				priority, ok := todotxtPriorityValue(value)
+				if !ok {
+					return Task{}, fmt.Errorf("invalid priority %q", value)
+				}
+				task.Priority = priority
+			case "status":
+				task.Status = value
+			case "notes":
+				task.Notes = value
+			case "uid":
+				task.UID = value
+			default:
+				task.Tags = append(task.Tags, key+":"+value)
+			}
+		default:
+			text = append(text, todotxtUnescape(word))
+		}
+	}
+	task.Task = strings.TrimSpace(strings.Join(text, " "))
+	if task.Task == "" {
+		return Task{}, fmt.Errorf("no task text")
+	}
+	return task, nil
+}
+
+func init() {
+	registerFormat(Format{
+		Name:        "todotxt",
+		Extensions:  []string{".txt"},
+		Description: "todo.txt",
+		Export: func(w io.Writer, m *Model) error {
+			for _, task := range m.Tasks {
+				if _, err := fmt.Fprintln(w, m.FormatTodoTxtLine(task)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Import: func(r io.Reader, _ ImportOptions) (ImportResult, error) {
+			var result ImportResult
+			scanner := bufio.NewScanner(r)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for n := 1; scanner.Scan(); n++ {
+				line := scanner.Text()
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				task, err := ParseTodoTxtLine(line)
+				if err != nil {
+					result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: %v", n, err))
+					continue
+				}
+				result.Tasks = append(result.Tasks, task)
+			}
+			return result, scanner.Err()
+		},
+	})
+}
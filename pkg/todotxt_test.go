@@ -0,0 +1,81 @@
+package todo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTodoTxtLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Task
+		wantErr bool
+	}{
+		{
+			line: "(A) Call the bank @Home +finance due:2026-10-20",
+			want: Task{Task: "Call the bank", Priority: "high", Context: "Home", Tags: []string{"finance"}, DueDate: "2026-10-20"},
+		},
+		{
+			line: "x 2026-10-18 2026-10-01 Pay rent @Home pri:B",
+			want: Task{Task: "Pay rent", Checked: true, Priority: "medium", Context: "Home"},
+		},
+		{
+			line: "(D) Someday maybe",
+			want: Task{Task: "Someday maybe", Priority: "low"},
+		},
+		{
+			line: "Review PR @Work @urgent sprint:42 status:Review notes:see%20the%20diff uid:abc-123",
+			want: Task{Task: "Review PR", Context: "Work", Tags: []string{"@urgent", "sprint:42"}, Status: "Review", Notes: "see the diff", UID: "abc-123"},
+		},
+		{
+			line: "Read https://example.com/page later",
+			want: Task{Task: "Read https://example.com/page later"},
+		},
+		{line: "Task due:tomorrow", wantErr: true},
+		{line: "Task pri:1", wantErr: true},
+		{line: "@Home +only", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTodoTxtLine(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTodoTxtLine(%q) = %+v, want an error", tt.line, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTodoTxtLine(%q): %v", tt.line, err)
+			continue
+		}
+		if !tasksEqual(got, tt.want) {
+			t.Errorf("ParseTodoTxtLine(%q)\n got %+v\nwant %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	m := &Model{Statuses: slices.Clone(DefaultStatuses)}
+	tests := []Task{
+		{Task: "x marks the spot", Context: "Home"},
+		{Task: "(A) is not a priority here", Context: "Home"},
+		{Task: "2026-10-20 is not a date here", Context: "Home"},
+		{Task: "Email @bob about +project key:value", Context: "Work"},
+		{Task: "100% done", Context: "Work Stuff", Tags: []string{"due:soon", "tag with space"}},
+		{Task: "Finished", Context: "Work", Checked: true, Priority: "low", Status: "Done"},
+		{Task: "Halfway", Context: "Work", Status: "In Progress", Notes: "line one\nline two\ttabbed", UID: "u-1"},
+	}
+	for _, task := range tests {
+		line := m.FormatTodoTxtLine(task)
+		got, err := ParseTodoTxtLine(line)
+		if err != nil {
+			t.Errorf("ParseTodoTxtLine(%q): %v", line, err)
+			continue
+		}
+		if task.Checked {
+			got.Status = task.Status
+		}
+		if !tasksEqual(got, task) {
+			t.Errorf("round trip through %q\n got %+v\nwant %+v", line, got, task)
+		}
+	}
+}
@@ -1,11 +1,30 @@
 package todo
 
 import (
+	"time"
+
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 )
 
+// ContextMeta holds optional per-context settings.
+type ContextMeta struct {
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+	Archived    bool   `json:"archived,omitempty"`
+	Aggregate   bool   `json:"aggregate,omitempty"` // show sub-contexts' tasks too
+}
+
+// UndoState is what undo restores: the tasks and the contexts with their
+// metadata.
+type UndoState struct {
+	Tasks       []Task
+	Contexts    []string
+	ContextMeta map[string]ContextMeta
+}
+
 // Task represents a single todo item
 type Task struct {
 	ID       int      `json:"id"`
@@ -15,6 +34,9 @@ type Task struct {
 	Priority string   `json:"priority,omitempty"` // low, medium, high
 	Tags     []string `json:"tags,omitempty"`
 	DueDate  string   `json:"due_date,omitempty"` // YYYY-MM-DD format
+	Status   string   `json:"status,omitempty"`   // one of Model.Statuses
+	Notes    string   `json:"notes,omitempty"`
+	UID      string   `json:"uid,omitempty"` // stable identifier for calendar clients
 }
 
 // ViewMode represents the current view
@@ -27,6 +49,18 @@ const (
 	InputView
 	DateInputView
 	RemoveTagView
+	NotesInputView
+	FileSwitcherView
+	ContextTreeView
+	CommandLineView
+)
+
+// KanbanLayout selects how the kanban board groups cards into columns
+type KanbanLayout int
+
+const (
+	KanbanByContext KanbanLayout = iota
+	KanbanByStatus
 )
 
 // InputMode represents different input dialogs
@@ -39,72 +73,132 @@ const (
 	RenameContextInput
 	AddTagInput
 	DeleteConfirmInput
+	DeleteTaskConfirmInput
+	OpenFileInput
+	ContextDescriptionInput
+	ContextColorInput
 )
 
 // Model represents the entire state of the todo application.
 type Model struct {
 	Tasks          []Task
 	Contexts       []string
+	ContextMeta    map[string]ContextMeta
+	ShowArchived   bool
 	CurrentContext string
 	SelectedIndex  int
+	NormalScrollY  int
+	TabScrollX     int
 	NextID         int
+	Statuses       []string
 
-	ViewMode  ViewMode
-	InputMode InputMode
+	ViewMode   ViewMode
+	ReturnView ViewMode
+	InputMode  InputMode
 
 	MovingMode    bool
 	MovingTaskID  int
 	KanbanScrollY int
 	KanbanScrollX int
+	KanbanCol     int
+	KanbanRow     int
+	KanbanLayout  KanbanLayout
 
-	TextInput       textinput.Model
-	DateInputs      []textinput.Model
-	DateInputIndex  int
-	RemoveTagIndex  int
-	RemoveTagChecks []bool
-	InputPrompt     string
+	TextInput           textinput.Model
+	NotesInput          textarea.Model
+	DateInputs          []textinput.Model
+	DateInputIndex      int
+	RemoveTagIndex      int
+	RemoveTagChecks     []bool
+	InputPrompt         string
+	FileEntries         []string
+	FileIndex           int
+	TreeIndex           int
+	CommandInput        textinput.Model
+	CommandHistory      []string
+	CommandHistoryIndex int
+	Completions         []string
+	CompletionIndex     int
+	TreeCollapsed       map[string]bool
 
-	WindowWidth  int
-	WindowHeight int
-	ErrorMessage string
+	WindowWidth   int
+	WindowHeight  int
+	ErrorMessage  string
+	DetailVisible bool
 
-	History    [][]Task
+	History    []UndoState
 	MaxHistory int
+	SavedTasks []Task // tasks as last loaded or saved, to tell hooks what changed
+
+	AsyncHooks   bool // run hooks in the background, as the TUI does
+	HooksQueued  bool // a save is waiting for hooks to run
+	HooksRunning bool
+	HookRound    int // tells the current background hook round from stale ones
+
+	WebhooksQueued bool // deliveries were queued since the last delivery round started
+	WebhookSending bool
+	WebhookRetryAt time.Time // when the scheduled retry round is due, zero if none is
 
 	KeyMap      KeyMap
 	Help        help.Model
 	HelpVisible bool
+	Theme       Theme
 
 	ConfigFilePath string
+	LoadErr        error // the note file exists but can't be read; saving is refused so it isn't overwritten
+	Settings       Settings
 }
 
 // KeyMap defines key bindings
 type KeyMap struct {
-	Up             key.Binding
-	Down           key.Binding
-	Left           key.Binding
-	Right          key.Binding
-	Toggle         key.Binding
-	Add            key.Binding
-	Edit           key.Binding
-	Delete         key.Binding
-	AddContext     key.Binding
-	RenameContext  key.Binding
-	DeleteContext  key.Binding
-	TogglePriority key.Binding
-	AddTag         key.Binding
-	RemoveTag      key.Binding
-	SetDueDate     key.Binding
-	ClearDueDate   key.Binding
-	KanbanView     key.Binding
-	StatsView      key.Binding
-	Undo           key.Binding
-	Move           key.Binding
-	Help           key.Binding
-	Quit           key.Binding
-	Back           key.Binding
-	Enter          key.Binding
-	Nav            key.Binding
+	Up               key.Binding
+	Down             key.Binding
+	Left             key.Binding
+	PageUp           key.Binding
+	PageDown         key.Binding
+	Top              key.Binding
+	Bottom           key.Binding
+	Right            key.Binding
+	Toggle           key.Binding
+	Add              key.Binding
+	Edit             key.Binding
+	EditNotes        key.Binding
+	OpenEditor       key.Binding
+	SaveNotes        key.Binding
+	ToggleDetail     key.Binding
+	Delete           key.Binding
+	AddContext       key.Binding
+	RenameContext    key.Binding
+	DeleteContext    key.Binding
+	JumpContext      key.Binding
+	MoveContextLeft  key.Binding
+	MoveContextRight key.Binding
+	ArchiveContext   key.Binding
+	ShowArchived     key.Binding
+	DescribeContext  key.Binding
+	ColorContext     key.Binding
+	ContextTree      key.Binding
+	AggregateContext key.Binding
+	Command          key.Binding
+	CopyMarkdown     key.Binding
+	TogglePriority   key.Binding
+	NextStatus       key.Binding
+	PrevStatus       key.Binding
+	AddTag           key.Binding
+	RemoveTag        key.Binding
+	SetDueDate       key.Binding
+	ClearDueDate     key.Binding
+	KanbanView       key.Binding
+	KanbanLayout     key.Binding
+	StatsView        key.Binding
+	SwitchFile       key.Binding
+	Undo             key.Binding
+	Move             key.Binding
+	Help             key.Binding
+	Quit             key.Binding
+	Back             key.Binding
+	Enter            key.Binding
+	Nav              key.Binding
 }
 
 // DefaultKeyMap returns default key bindings
@@ -118,6 +212,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("down", "j"),
 			key.WithHelp("↓/j", "move down"),
 		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "page up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdn", "page down"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("g", "home"),
+			key.WithHelp("g", "top"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("G", "end"),
+			key.WithHelp("G", "bottom"),
+		),
 		Left: key.NewBinding(
 			key.WithKeys("left", "h"),
 			key.WithHelp("←/h", "prev context"),
@@ -138,6 +248,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit"),
 		),
+		EditNotes: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "notes"),
+		),
+		OpenEditor: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "edit in $EDITOR"),
+		),
+		SaveNotes: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "save notes"),
+		),
+		ToggleDetail: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "details"),
+		),
 		Delete: key.NewBinding(
 			key.WithKeys("d"),
 			key.WithHelp("d", "delete"),
@@ -154,10 +280,62 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("D"),
 			key.WithHelp("D", "delete context"),
 		),
+		JumpContext: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "jump to context"),
+		),
+		MoveContextLeft: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "move context left"),
+		),
+		MoveContextRight: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "move context right"),
+		),
+		ArchiveContext: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "archive context"),
+		),
+		ShowArchived: key.NewBinding(
+			key.WithKeys("ctrl+a"),
+			key.WithHelp("ctrl+a", "show archived"),
+		),
+		DescribeContext: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "describe context"),
+		),
+		ColorContext: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "context colour"),
+		),
+		ContextTree: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "context tree"),
+		),
+		AggregateContext: key.NewBinding(
+			key.WithKeys("+"),
+			key.WithHelp("+", "include sub-contexts"),
+		),
+		Command: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "command line"),
+		),
+		CopyMarkdown: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy context as Markdown"),
+		),
 		TogglePriority: key.NewBinding(
 			key.WithKeys("p"),
 			key.WithHelp("p", "priority"),
 		),
+		NextStatus: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "next status"),
+		),
+		PrevStatus: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "prev status"),
+		),
 		AddTag: key.NewBinding(
 			key.WithKeys("t"),
 			key.WithHelp("t", "add tag"),
@@ -178,10 +356,18 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("v"),
 			key.WithHelp("v", "kanban"),
 		),
+		KanbanLayout: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "kanban by context/status"),
+		),
 		StatsView: key.NewBinding(
 			key.WithKeys("s"),
 			key.WithHelp("s", "stats"),
 		),
+		SwitchFile: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "switch list"),
+		),
 		Undo: key.NewBinding(
 			key.WithKeys("z"),
 			key.WithHelp("z", "undo"),
@@ -220,11 +406,11 @@ func (k KeyMap) ShortHelp() []key.Binding {
 
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Nav},
-		{k.Toggle, k.Add, k.Edit, k.Delete, k.Move},
-		{k.AddContext, k.RenameContext, k.DeleteContext},
-		{k.TogglePriority, k.AddTag, k.RemoveTag, k.SetDueDate, k.ClearDueDate},
-		{k.KanbanView, k.StatsView},
-		{k.Undo, k.Help, k.Back, k.Quit},
+		{k.Nav, k.PageUp, k.PageDown, k.Top, k.Bottom},
+		{k.Toggle, k.Add, k.Edit, k.EditNotes, k.OpenEditor, k.Delete, k.Move, k.ToggleDetail},
+		{k.AddContext, k.RenameContext, k.DeleteContext, k.JumpContext, k.MoveContextLeft, k.MoveContextRight, k.ArchiveContext, k.ShowArchived, k.DescribeContext, k.ColorContext, k.ContextTree, k.AggregateContext},
+		{k.TogglePriority, k.NextStatus, k.PrevStatus, k.AddTag, k.RemoveTag, k.SetDueDate, k.ClearDueDate},
+		{k.KanbanView, k.KanbanLayout, k.StatsView, k.SwitchFile, k.CopyMarkdown},
+		{k.Command, k.Undo, k.Help, k.Back, k.Quit},
 	}
 }
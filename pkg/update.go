@@ -2,21 +2,51 @@ package todo
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbletea"
 )
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case webhookResultMsg, webhookRetryMsg:
+		return m.updateWebhooks(msg, nil)
+	case hooksResultMsg:
+		m, cmd := m.updateHooks(msg, nil)
+		return m.updateWebhooks(msg, cmd)
+	}
+	model, cmd := m.update(msg)
+	m, cmd = model.(Model).updateHooks(msg, cmd)
+	return m.updateWebhooks(msg, cmd)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.WindowWidth = msg.Width
 		m.WindowHeight = msg.Height
 		m.Help.Width = msg.Width
 		m.TextInput.Width = msg.Width - 20
+		m.NotesInput.SetWidth(msg.Width - 20)
+		m.NotesInput.SetHeight(max(3, msg.Height-12))
+		if m.ViewMode == KanbanView {
+			m.scrollKanbanToCursor()
+		}
+		m.ScrollToSelection()
 		return m, tea.ClearScreen
 
+	case tea.MouseMsg:
+		return m.UpdateMouse(msg)
+
+	case editorFinishedMsg:
+		m.finishEditing(msg)
+		return m, nil
+
 	case tea.KeyMsg:
 		if m.HelpVisible {
 			switch {
@@ -35,6 +65,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.UpdateDateInputMode(msg)
 		case RemoveTagView:
 			return m.UpdateRemoveTagMode(msg)
+		case NotesInputView:
+			return m.UpdateNotesInputMode(msg)
+		case FileSwitcherView:
+			return m.UpdateFileSwitcher(msg)
+		case ContextTreeView:
+			return m.UpdateContextTree(msg)
+		case CommandLineView:
+			return m.UpdateCommandLine(msg)
 		}
 
 		switch m.ViewMode {
@@ -55,7 +93,7 @@ func (m Model) UpdateInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.KeyMap.Back):
-		m.ViewMode = NormalView
+		m.CloseDialog()
 		return m, nil
 
 	case key.Matches(msg, m.KeyMap.Enter):
@@ -91,15 +129,37 @@ func (m Model) UpdateInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.AddTagToCurrentTask(input)
 				m.SaveConfig()
 			}
+		case ContextDescriptionInput:
+			m.SetCurrentContextDescription(input)
+			m.SaveConfig()
+		case ContextColorInput:
+			m.SetCurrentContextColor(input)
+			m.SaveConfig()
 		case DeleteConfirmInput:
 			if strings.ToLower(input) == "y" {
 				m.SaveStateForUndo()
 				m.DeleteContext()
 				m.SaveConfig()
 			}
+		case OpenFileInput:
+			if input != "" {
+				path, err := filepath.Abs(ExpandHome(input))
+				if err != nil {
+					m.ErrorMessage = fmt.Sprintf("Invalid path: %v", err)
+				} else {
+					m.SwitchFile(path)
+					return m, nil
+				}
+			}
+		case DeleteTaskConfirmInput:
+			if strings.ToLower(input) == "y" {
+				m.SaveStateForUndo()
+				m.DeleteCurrentTask()
+				m.SaveConfig()
+			}
 		}
 
-		m.ViewMode = NormalView
+		m.CloseDialog()
 		return m, nil
 	}
 
@@ -112,7 +172,7 @@ func (m Model) UpdateDateInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.KeyMap.Back):
-		m.ViewMode = NormalView
+		m.CloseDialog()
 		return m, nil
 
 	case key.Matches(msg, m.KeyMap.Enter):
@@ -123,7 +183,7 @@ func (m Model) UpdateDateInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.SaveStateForUndo()
 		m.SetDueDateForCurrentTask(dateStr)
 		m.SaveConfig()
-		m.ViewMode = NormalView
+		m.CloseDialog()
 		return m, nil
 
 	case key.Matches(msg, m.KeyMap.Up):
@@ -141,17 +201,169 @@ func (m Model) UpdateDateInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) UpdateNotesInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.KeyMap.Back):
+		m.NotesInput.Blur()
+		m.CloseDialog()
+		return m, nil
+
+	case key.Matches(msg, m.KeyMap.SaveNotes):
+		m.SaveStateForUndo()
+		m.SetNotesForCurrentTask(m.NotesInput.Value())
+		m.SaveConfig()
+		m.NotesInput.Blur()
+		m.CloseDialog()
+		return m, nil
+	}
+
+	m.NotesInput, cmd = m.NotesInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) UpdateFileSwitcher(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.KeyMap.Back), key.Matches(msg, m.KeyMap.SwitchFile):
+		m.CloseDialog()
+
+	case key.Matches(msg, m.KeyMap.Enter):
+		if m.FileIndex < len(m.FileEntries) {
+			path := m.FileEntries[m.FileIndex]
+			if path == m.ConfigFilePath {
+				m.CloseDialog()
+			} else {
+				m.SwitchFile(path)
+			}
+		}
+
+	case key.Matches(msg, m.KeyMap.Add):
+		returnView := m.ReturnView
+		m.ShowInputDialog(OpenFileInput, "Open note file:")
+		m.ReturnView = returnView
+
+	case key.Matches(msg, m.KeyMap.Up):
+		if m.FileIndex > 0 {
+			m.FileIndex--
+		}
+
+	case key.Matches(msg, m.KeyMap.Down):
+		if m.FileIndex < len(m.FileEntries)-1 {
+			m.FileIndex++
+		}
+	}
+	return m, nil
+}
+
+func (m Model) UpdateCommandLine(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.KeyMap.Back):
+		m.CloseDialog()
+		return m, nil
+
+	case key.Matches(msg, m.KeyMap.Enter):
+		cmd = m.RunCommandLine(m.CommandInput.Value())
+		m.CloseDialog()
+		return m, cmd
+
+	case msg.Type == tea.KeyTab:
+		m.CycleCompletion(1)
+		return m, nil
+
+	case msg.Type == tea.KeyShiftTab:
+		m.CycleCompletion(-1)
+		return m, nil
+
+	case msg.Type == tea.KeyUp, msg.Type == tea.KeyDown:
+		if msg.Type == tea.KeyUp {
+			m.CommandHistoryIndex = max(0, m.CommandHistoryIndex-1)
+		} else {
+			m.CommandHistoryIndex = min(len(m.CommandHistory), m.CommandHistoryIndex+1)
+		}
+		m.CommandInput.SetValue("")
+		if m.CommandHistoryIndex < len(m.CommandHistory) {
+			m.CommandInput.SetValue(m.CommandHistory[m.CommandHistoryIndex])
+		}
+		m.CommandInput.CursorEnd()
+		m.Completions = nil
+		return m, nil
+	}
+
+	m.Completions = nil
+	m.CommandInput, cmd = m.CommandInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) UpdateContextTree(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.contextTreeRows()
+	hasChildren := func(i int) bool {
+		return i+1 < len(rows) && isSubContext(rows[i+1], rows[i]) ||
+			m.TreeCollapsed[rows[i]] && len(m.subContexts(rows[i])) > 0
+	}
+
+	switch {
+	case key.Matches(msg, m.KeyMap.Back), key.Matches(msg, m.KeyMap.ContextTree):
+		m.CloseDialog()
+
+	case key.Matches(msg, m.KeyMap.Enter):
+		if m.TreeIndex < len(rows) {
+			m.CurrentContext = rows[m.TreeIndex]
+			m.SelectedIndex = 0
+		}
+		m.CloseDialog()
+
+	case key.Matches(msg, m.KeyMap.Up):
+		if m.TreeIndex > 0 {
+			m.TreeIndex--
+		}
+
+	case key.Matches(msg, m.KeyMap.Down):
+		if m.TreeIndex < len(rows)-1 {
+			m.TreeIndex++
+		}
+
+	case key.Matches(msg, m.KeyMap.Left):
+		if m.TreeIndex >= len(rows) {
+			break
+		}
+		if hasChildren(m.TreeIndex) && !m.TreeCollapsed[rows[m.TreeIndex]] {
+			m.SetTreeCollapsed(true)
+		} else if parent := contextParent(rows[m.TreeIndex]); parent != "" {
+			m.TreeIndex = slices.Index(rows, parent)
+		}
+
+	case key.Matches(msg, m.KeyMap.Right):
+		if m.TreeIndex >= len(rows) || !hasChildren(m.TreeIndex) {
+			break
+		}
+		if m.TreeCollapsed[rows[m.TreeIndex]] {
+			m.SetTreeCollapsed(false)
+		} else {
+			m.TreeIndex++
+		}
+
+	case key.Matches(msg, m.KeyMap.Toggle):
+		if m.TreeIndex < len(rows) && hasChildren(m.TreeIndex) {
+			m.SetTreeCollapsed(!m.TreeCollapsed[rows[m.TreeIndex]])
+		}
+	}
+	return m, nil
+}
+
 func (m Model) UpdateRemoveTagMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.KeyMap.Back):
-		m.ViewMode = NormalView
+		m.CloseDialog()
 		return m, nil
 
 	case key.Matches(msg, m.KeyMap.Enter):
 		m.SaveStateForUndo()
 		m.RemoveTagsFromCurrentTask()
 		m.SaveConfig()
-		m.ViewMode = NormalView
+		m.CloseDialog()
 		return m, nil
 
 	case key.Matches(msg, m.KeyMap.Up):
@@ -178,7 +390,21 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 
 	case key.Matches(msg, m.KeyMap.Back):
-		return m, nil
+		m.DetailVisible = false
+
+	case key.Matches(msg, m.KeyMap.ToggleDetail):
+		m.DetailVisible = !m.DetailVisible
+
+	case key.Matches(msg, m.KeyMap.EditNotes):
+		if len(m.GetFilteredTasks()) > 0 {
+			m.ShowNotesDialog()
+			return m, textarea.Blink
+		}
+
+	case key.Matches(msg, m.KeyMap.OpenEditor):
+		if len(m.GetFilteredTasks()) > 0 {
+			return m, m.OpenInEditor()
+		}
 
 	case key.Matches(msg, m.KeyMap.Up):
 		if m.MovingMode {
@@ -194,12 +420,67 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.MoveDown()
 		}
 
+	case key.Matches(msg, m.KeyMap.PageUp):
+		m.MoveBy(-m.normalListHeight())
+
+	case key.Matches(msg, m.KeyMap.PageDown):
+		m.MoveBy(m.normalListHeight())
+
+	case key.Matches(msg, m.KeyMap.Top):
+		m.MoveBy(-len(m.GetFilteredTasks()))
+
+	case key.Matches(msg, m.KeyMap.Bottom):
+		m.MoveBy(len(m.GetFilteredTasks()))
+
 	case key.Matches(msg, m.KeyMap.Left):
 		m.PreviousContext()
 
 	case key.Matches(msg, m.KeyMap.Right):
 		m.NextContext()
 
+	case key.Matches(msg, m.KeyMap.JumpContext):
+		m.JumpToContext(slices.Index(m.KeyMap.JumpContext.Keys(), msg.String()))
+
+	case key.Matches(msg, m.KeyMap.MoveContextLeft):
+		m.MoveCurrentContext(-1)
+		m.SaveConfig()
+
+	case key.Matches(msg, m.KeyMap.MoveContextRight):
+		m.MoveCurrentContext(1)
+		m.SaveConfig()
+
+	case key.Matches(msg, m.KeyMap.ArchiveContext):
+		m.ToggleArchiveCurrentContext()
+		m.SaveConfig()
+
+	case key.Matches(msg, m.KeyMap.ShowArchived):
+		m.ToggleShowArchived()
+
+	case key.Matches(msg, m.KeyMap.ContextTree):
+		m.ShowContextTree()
+
+	case key.Matches(msg, m.KeyMap.Command):
+		m.ShowCommandLine()
+
+	case key.Matches(msg, m.KeyMap.CopyMarkdown):
+		if err := clipboard.WriteAll(m.MarkdownContext()); err != nil {
+			m.ErrorMessage = fmt.Sprintf("Copy failed: %v", err)
+		} else {
+			m.ErrorMessage = fmt.Sprintf("Copied '%s' as Markdown", m.CurrentContext)
+		}
+
+	case key.Matches(msg, m.KeyMap.AggregateContext):
+		m.ToggleAggregateCurrentContext()
+		m.SaveConfig()
+
+	case key.Matches(msg, m.KeyMap.DescribeContext):
+		m.ShowInputDialog(ContextDescriptionInput, fmt.Sprintf("Description for '%s':", m.CurrentContext))
+		m.TextInput.SetValue(m.ContextMeta[m.CurrentContext].Description)
+
+	case key.Matches(msg, m.KeyMap.ColorContext):
+		m.ShowInputDialog(ContextColorInput, fmt.Sprintf("Colour for '%s' (#RRGGBB or 0-255, empty to clear):", m.CurrentContext))
+		m.TextInput.SetValue(m.ContextMeta[m.CurrentContext].Color)
+
 	case key.Matches(msg, m.KeyMap.Toggle):
 		if len(m.GetFilteredTasks()) > 0 {
 			m.SaveStateForUndo()
@@ -219,9 +500,13 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.KeyMap.Delete):
 		if len(m.GetFilteredTasks()) > 0 {
-			m.SaveStateForUndo()
-			m.DeleteCurrentTask()
-			m.SaveConfig()
+			if m.Settings.ConfirmDelete {
+				m.ShowDeleteTaskDialog()
+			} else {
+				m.SaveStateForUndo()
+				m.DeleteCurrentTask()
+				m.SaveConfig()
+			}
 		}
 
 	case key.Matches(msg, m.KeyMap.AddContext):
@@ -232,8 +517,12 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.TextInput.SetValue(m.CurrentContext)
 
 	case key.Matches(msg, m.KeyMap.DeleteContext):
-		if len(m.Contexts) > 1 {
-			m.ShowInputDialog(DeleteConfirmInput, fmt.Sprintf("Delete context '%s'? (y/n):", m.CurrentContext))
+		if m.canDeleteCurrentContext() {
+			prompt := fmt.Sprintf("Delete context '%s'? (y/n):", m.CurrentContext)
+			if n := len(m.subContexts(m.CurrentContext)); n > 0 {
+				prompt = fmt.Sprintf("Delete context '%s' and its %d sub-contexts? (y/n):", m.CurrentContext, n)
+			}
+			m.ShowInputDialog(DeleteConfirmInput, prompt)
 		} else {
 			m.ErrorMessage = "Cannot delete the only context"
 		}
@@ -245,6 +534,20 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.SaveConfig()
 		}
 
+	case key.Matches(msg, m.KeyMap.NextStatus):
+		if len(m.GetFilteredTasks()) > 0 {
+			m.SaveStateForUndo()
+			m.AdvanceCurrentTaskStatus(1)
+			m.SaveConfig()
+		}
+
+	case key.Matches(msg, m.KeyMap.PrevStatus):
+		if len(m.GetFilteredTasks()) > 0 {
+			m.SaveStateForUndo()
+			m.AdvanceCurrentTaskStatus(-1)
+			m.SaveConfig()
+		}
+
 	case key.Matches(msg, m.KeyMap.AddTag):
 		if len(m.GetFilteredTasks()) > 0 {
 			m.ShowInputDialog(AddTagInput, "Add tag:")
@@ -268,11 +571,14 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case key.Matches(msg, m.KeyMap.KanbanView):
-		m.ViewMode = KanbanView
+		m.ShowKanbanView()
 
 	case key.Matches(msg, m.KeyMap.StatsView):
 		m.ViewMode = StatsView
 
+	case key.Matches(msg, m.KeyMap.SwitchFile):
+		m.ShowFileSwitcher()
+
 	case key.Matches(msg, m.KeyMap.Undo):
 		m.Undo()
 		m.SaveConfig()
@@ -292,27 +598,141 @@ func (m Model) UpdateNormalView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	m.ScrollToSelection()
 	return m, nil
 }
 
 func (m Model) UpdateKanbanView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, focused := m.KanbanFocusedTask()
+
 	switch {
+	case key.Matches(msg, m.KeyMap.Back) && m.MovingMode:
+		m.MovingMode = false
+		m.SaveConfig()
+
 	case key.Matches(msg, m.KeyMap.Back), key.Matches(msg, m.KeyMap.Quit), key.Matches(msg, m.KeyMap.KanbanView):
+		if m.MovingMode {
+			m.MovingMode = false
+			m.SaveConfig()
+		}
 		m.ViewMode = NormalView
 		m.KanbanScrollY = 0
 		m.KanbanScrollX = 0
+		m.ScrollToSelection()
+
 	case key.Matches(msg, m.KeyMap.Up):
-		if m.KanbanScrollY > 0 {
-			m.KanbanScrollY--
+		if m.MovingMode {
+			m.ReorderKanbanCard(-1)
+		} else {
+			m.MoveKanbanCursor(0, -1)
 		}
+
 	case key.Matches(msg, m.KeyMap.Down):
-		m.KanbanScrollY++
+		if m.MovingMode {
+			m.ReorderKanbanCard(1)
+		} else {
+			m.MoveKanbanCursor(0, 1)
+		}
+
 	case key.Matches(msg, m.KeyMap.Left):
-		if m.KanbanScrollX > 0 {
-			m.KanbanScrollX--
+		if m.MovingMode {
+			m.ShiftKanbanCard(-1)
+		} else {
+			m.MoveKanbanCursor(-1, 0)
 		}
+
 	case key.Matches(msg, m.KeyMap.Right):
-		m.KanbanScrollX++
+		if m.MovingMode {
+			m.ShiftKanbanCard(1)
+		} else {
+			m.MoveKanbanCursor(1, 0)
+		}
+
+	case key.Matches(msg, m.KeyMap.KanbanLayout):
+		if m.MovingMode {
+			m.MovingMode = false
+			m.SaveConfig()
+		}
+		m.ToggleKanbanLayout()
+
+	case key.Matches(msg, m.KeyMap.Move):
+		if focused {
+			m.MovingMode = !m.MovingMode
+			if m.MovingMode {
+				m.SaveStateForUndo()
+				m.MovingTaskID = m.GetCurrentTask().ID
+			} else {
+				m.SaveConfig()
+			}
+		}
+
+	case m.MovingMode:
+		// Only movement keys apply while a card is grabbed.
+
+	case key.Matches(msg, m.KeyMap.Toggle):
+		if focused {
+			m.SaveStateForUndo()
+			m.ToggleCurrentTask()
+			m.SaveConfig()
+			m.refocusKanban()
+		}
+
+	case key.Matches(msg, m.KeyMap.NextStatus), key.Matches(msg, m.KeyMap.PrevStatus):
+		if focused {
+			delta := 1
+			if key.Matches(msg, m.KeyMap.PrevStatus) {
+				delta = -1
+			}
+			m.SaveStateForUndo()
+			m.AdvanceCurrentTaskStatus(delta)
+			m.SaveConfig()
+			m.refocusKanban()
+		}
+
+	case key.Matches(msg, m.KeyMap.Add):
+		m.ShowInputDialog(AddTaskInput, fmt.Sprintf("Add new task to %s:", m.CurrentContext))
+
+	case key.Matches(msg, m.KeyMap.Edit):
+		if focused {
+			task := m.GetCurrentTask()
+			m.ShowInputDialog(EditTaskInput, "Edit task:")
+			m.TextInput.SetValue(task.Task)
+		}
+
+	case key.Matches(msg, m.KeyMap.OpenEditor):
+		if focused {
+			return m, m.OpenInEditor()
+		}
+
+	case key.Matches(msg, m.KeyMap.Delete):
+		if focused {
+			if m.Settings.ConfirmDelete {
+				m.ShowDeleteTaskDialog()
+			} else {
+				m.SaveStateForUndo()
+				m.DeleteCurrentTask()
+				m.SaveConfig()
+				m.syncKanbanSelection()
+			}
+		}
+
+	case key.Matches(msg, m.KeyMap.TogglePriority):
+		if focused {
+			m.SaveStateForUndo()
+			m.ToggleCurrentTaskPriority()
+			m.SaveConfig()
+		}
+
+	case key.Matches(msg, m.KeyMap.Undo):
+		m.Undo()
+		m.SaveConfig()
+		m.syncKanbanSelection()
+
+	case key.Matches(msg, m.KeyMap.Command):
+		m.ShowCommandLine()
+
+	case key.Matches(msg, m.KeyMap.Help):
+		m.HelpVisible = true
 	}
 	return m, nil
 }
@@ -3,57 +3,11 @@ package todo
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Styles
-var (
-	baseStyle = lipgloss.NewStyle().
-			PaddingLeft(1).
-			PaddingRight(1)
-
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFDF5")).
-			Background(lipgloss.Color("#25A065")).
-			Padding(0, 1).
-			Bold(true)
-
-	taskStyle = lipgloss.NewStyle().
-			PaddingLeft(2)
-
-	selectedTaskStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#EE6FF8")).
-				Background(lipgloss.Color("#313244")).
-				PaddingLeft(2)
-
-	completedTaskStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#A6E3A1")).
-				Strikethrough(true)
-
-	highPriorityStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#F38BA8"))
-
-	mediumPriorityStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FAB387"))
-
-	lowPriorityStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#F9E2AF"))
-
-	contextStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#89B4FA")).
-			Bold(true)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#F38BA8")).
-			Bold(true)
-
-	inputStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			Padding(1).
-			Margin(1)
-)
-
 func (m Model) View() string {
 	if m.HelpVisible {
 		return m.renderFullHelpView()
@@ -66,6 +20,14 @@ func (m Model) View() string {
 		return m.RenderDateInputView()
 	case RemoveTagView:
 		return m.RenderRemoveTagView()
+	case NotesInputView:
+		return m.RenderNotesInputView()
+	case FileSwitcherView:
+		return m.RenderFileSwitcherView()
+	case ContextTreeView:
+		return m.RenderContextTreeView()
+	case CommandLineView:
+		return m.RenderCommandLine()
 	case KanbanView:
 		return m.RenderKanbanView()
 	case StatsView:
@@ -76,65 +38,193 @@ func (m Model) View() string {
 }
 
 func (m Model) renderFullHelpView() string {
-	helpBoxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#89B4FA")).
-		Padding(1, 2)
+	helpBoxStyle := m.Theme.Pane.Padding(1, 2)
 
 	m.Help.ShowAll = true
 	helpContent := m.Help.View(m.KeyMap)
 	titledHelp := lipgloss.JoinVertical(lipgloss.Left,
-		titleStyle.Render("Keybindings"),
+		m.Theme.Title.Render("Keybindings"),
 		helpContent,
 	)
 
 	return lipgloss.Place(m.WindowWidth, m.WindowHeight, lipgloss.Center, lipgloss.Center, helpBoxStyle.Render(titledHelp))
 }
 
+// normalHeaderLines returns the lines pinned above the task list.
+func (m *Model) normalHeaderLines() []string {
+	tabs, _ := m.contextTabBar()
+	description := m.ContextMeta[m.CurrentContext].Description
+	return []string{tabs + m.fileLabel(), m.Theme.Muted.Render(description)}
+}
+
+// normalFooterLines returns the lines pinned below the task list: the scroll
+// indicator when the list overflows, and the error message.
+func (m *Model) normalFooterLines() []string {
+	var lines []string
+	if m.normalListOverflows() {
+		lines = append(lines, m.scrollIndicator(len(m.GetFilteredTasks())))
+	}
+	if m.ErrorMessage != "" {
+		lines = append(lines, "", m.Theme.Error.Render(m.ErrorMessage))
+	}
+	return lines
+}
+
+func (m *Model) scrollIndicator(total int) string {
+	height := m.normalListHeight()
+	first := m.NormalScrollY + 1
+	last := min(m.NormalScrollY+height, total)
+
+	position := fmt.Sprintf("%d%%", m.NormalScrollY*100/max(1, total-height))
+	switch {
+	case m.NormalScrollY == 0:
+		position = "Top"
+	case last == total:
+		position = "Bot"
+	}
+	return m.Theme.Muted.Render(fmt.Sprintf("%d-%d of %d (%s)", first, last, total, position))
+}
+
+// detailSplitMinWidth is the narrowest window that shows the detail pane
+// beside the list rather than instead of it.
+const detailSplitMinWidth = 100
+
+// detailListWidth is the width of the list when the detail pane is split
+// beside it.
+func (m *Model) detailListWidth() int {
+	return m.WindowWidth * 3 / 5
+}
+
 func (m Model) RenderNormalView() string {
-	var mainContent strings.Builder
+	if m.DetailVisible && m.WindowWidth < detailSplitMinWidth {
+		return m.RenderDetailView()
+	}
 
-	contextText := fmt.Sprintf("Context: %s", m.CurrentContext)
-	mainContent.WriteString(titleStyle.Render(contextText) + "\n\n")
+	m.ScrollToSelection()
+	lines := m.normalHeaderLines()
 
+	var list []string
 	tasks := m.GetFilteredTasks()
 	if len(tasks) == 0 {
 		if len(m.Contexts) == 0 {
-			mainContent.WriteString("No contexts exist. Press 'n' to create one.\n")
+			list = append(list, "No contexts exist. Press 'n' to create one.")
 		} else {
-			mainContent.WriteString("No tasks in this context. Press 'a' to add one.\n")
+			list = append(list, "No tasks in this context. Press 'a' to add one.")
 		}
 	} else {
-		for i, task := range tasks {
-			taskLine := m.RenderTask(task, i == m.SelectedIndex, m.MovingMode && task.ID == m.MovingTaskID)
-			mainContent.WriteString(taskLine + "\n")
+		end := len(tasks)
+		if m.WindowHeight > 0 {
+			end = min(end, m.NormalScrollY+m.normalListHeight())
+		}
+		for i := m.NormalScrollY; i < end; i++ {
+			task := tasks[i]
+			list = append(list, m.RenderTask(task, i == m.SelectedIndex, m.MovingMode && task.ID == m.MovingTaskID))
 		}
 	}
-
-	if m.ErrorMessage != "" {
-		mainContent.WriteString("\n" + errorStyle.Render(m.ErrorMessage) + "\n")
+	for m.WindowHeight > 0 && len(list) < m.normalListHeight() {
+		list = append(list, "")
 	}
 
-	return baseStyle.Render(mainContent.String())
+	lines = append(lines, list...)
+	lines = append(lines, m.normalFooterLines()...)
+	body := strings.Join(lines, "\n")
+
+	if m.DetailVisible {
+		listWidth := m.detailListWidth()
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(listWidth).MaxWidth(listWidth).Render(body),
+			m.renderDetailPane(m.WindowWidth-listWidth-m.Theme.Base.GetHorizontalPadding()),
+		)
+	}
+	return m.Theme.Base.Render(body)
 }
 
-func (m Model) RenderTask(task Task, selected, moving bool) string {
-	checkbox := "[ ]"
-	if task.Checked {
-		checkbox = "[✓]"
+// renderDetailPane renders everything known about the selected task in a
+// bordered box of the given outer width.
+func (m Model) renderDetailPane(width int) string {
+	paneStyle := m.Theme.Pane.Padding(0, 1)
+	inner := width - paneStyle.GetHorizontalFrameSize()
+	paneStyle = paneStyle.Width(width - paneStyle.GetHorizontalBorderSize())
+	if m.WindowHeight > 0 {
+		paneStyle = paneStyle.Height(m.WindowHeight - paneStyle.GetVerticalBorderSize())
 	}
 
-	priority := ""
+	tasks := m.GetFilteredTasks()
+	if len(tasks) == 0 {
+		return paneStyle.Render("No task selected.")
+	}
+	task := m.GetCurrentTask()
+
+	field := func(label, value string) string {
+		if value == "" {
+			value = m.Theme.Muted.Render("-")
+		}
+		return fmt.Sprintf("%s %s", m.Theme.Context.Render(label+":"), value)
+	}
+	lines := []string{
+		m.Theme.Title.Render(fmt.Sprintf("#%d", task.ID)),
+		"",
+		lipgloss.NewStyle().Bold(true).Render(task.Task),
+		"",
+		field("Context", task.Context),
+		field("Status", task.Status),
+		field("Priority", task.Priority),
+		field("Tags", strings.Join(task.Tags, ", ")),
+		field("Due", m.Settings.FormatDate(task.DueDate)),
+		"",
+		m.Theme.Context.Render("Notes:"),
+	}
+	if task.Notes == "" {
+		lines = append(lines, m.Theme.Muted.Render("No notes. Press 'N' to add some."))
+	} else {
+		lines = append(lines, task.Notes)
+	}
+
+	return paneStyle.Render(lipgloss.NewStyle().Width(inner).Render(strings.Join(lines, "\n")))
+}
+
+// RenderDetailView shows the detail pane on its own for narrow terminals.
+func (m Model) RenderDetailView() string {
+	return m.renderDetailPane(m.WindowWidth)
+}
+
+// priorityMarker renders the exclamation marks shown before a task.
+func (m *Model) priorityMarker(task Task) string {
 	switch task.Priority {
 	case "high":
-		priority = highPriorityStyle.Render("!!! ")
+		return m.Theme.HighPriority.Render("!!! ")
 	case "medium":
-		priority = mediumPriorityStyle.Render("!! ")
+		return m.Theme.MediumPriority.Render("!! ")
 	case "low":
-		priority = lowPriorityStyle.Render("! ")
+		return m.Theme.LowPriority.Render("! ")
 	}
+	return ""
+}
+
+// taskRowStyle is the base style of a task line in the normal view.
+func (m *Model) taskRowStyle(task Task) lipgloss.Style {
+	if task.Checked {
+		return m.Theme.CompletedTask
+	}
+	return m.Theme.Task
+}
+
+func (m Model) RenderTask(task Task, selected, moving bool) string {
+	checkbox := "[ ]"
+	status := ""
+	if task.Checked {
+		checkbox = "[✓]"
+	} else if m.StatusIndex(task) > 0 {
+		checkbox = "[~]"
+		status = fmt.Sprintf(" (%s)", task.Status)
+	}
+
+	priority := m.priorityMarker(task)
 
 	taskText := task.Task
+	if task.Notes != "" {
+		taskText += " ✎"
+	}
 
 	tags := ""
 	if len(task.Tags) > 0 {
@@ -143,18 +233,19 @@ func (m Model) RenderTask(task Task, selected, moving bool) string {
 
 	dueDate := ""
 	if task.DueDate != "" {
-		dueDate = fmt.Sprintf(" [Due: %s]", task.DueDate)
+		dueDate = fmt.Sprintf(" [Due: %s]", m.Settings.FormatDate(task.DueDate))
 	}
 
-	text := fmt.Sprintf("%s %s%s%s", checkbox, taskText, tags, dueDate)
-
-	style := taskStyle
-	if task.Checked {
-		style = completedTaskStyle
+	subContext := ""
+	if isSubContext(task.Context, m.CurrentContext) {
+		subContext = " @" + strings.TrimPrefix(task.Context, m.CurrentContext+contextSeparator)
 	}
 
+	text := fmt.Sprintf("%s %s%s%s%s%s", checkbox, taskText, subContext, status, tags, dueDate)
+
+	style := m.taskRowStyle(task)
 	if selected {
-		style = style.Background(lipgloss.Color("#313244"))
+		style = style.Inherit(m.Theme.Highlight)
 	}
 
 	if moving {
@@ -165,12 +256,102 @@ func (m Model) RenderTask(task Task, selected, moving bool) string {
 }
 
 func (m Model) RenderInputView() string {
-	content := inputStyle.Render(
+	content := m.Theme.Input.Render(
 		fmt.Sprintf("%s\n\n%s", m.InputPrompt, m.TextInput.View()),
 	)
 	return lipgloss.Place(m.WindowWidth, m.WindowHeight, lipgloss.Center, lipgloss.Center, content)
 }
 
+func (m Model) RenderFileSwitcherView() string {
+	var content strings.Builder
+	content.WriteString("Switch list:\n\n")
+	for i, path := range m.FileEntries {
+		marker := "  "
+		if path == m.ConfigFilePath {
+			marker = "• "
+		}
+		line := marker + path
+		if i == m.FileIndex {
+			content.WriteString(m.Theme.SelectedTask.Render(line) + "\n")
+		} else {
+			content.WriteString(line + "\n")
+		}
+	}
+	content.WriteString("\n" + m.Theme.Muted.Render("enter open • a open another file • esc cancel"))
+	return lipgloss.Place(m.WindowWidth, m.WindowHeight, lipgloss.Center, lipgloss.Center, m.Theme.Input.Render(content.String()))
+}
+
+// RenderCommandLine draws the command line, and the completions being cycled
+// through, over the bottom of the view it was opened from.
+func (m Model) RenderCommandLine() string {
+	under := m
+	under.ViewMode = m.ReturnView
+	lines := strings.Split(under.View(), "\n")
+
+	bottom := []string{m.CommandInput.View()}
+	if len(m.Completions) > 1 {
+		words := make([]string, len(m.Completions))
+		for i, completion := range m.Completions {
+			words[i] = completion[strings.LastIndex(strings.TrimRight(completion, " "), " ")+1:]
+			if i == m.CompletionIndex {
+				words[i] = m.Theme.Highlight.Render(words[i])
+			}
+		}
+		bottom = append([]string{m.Theme.Muted.Render(strings.Join(words, "  "))}, bottom...)
+	}
+
+	if m.WindowHeight > 0 {
+		height := max(0, m.WindowHeight-len(bottom))
+		lines = lines[:min(len(lines), height)]
+		for len(lines) < height {
+			lines = append(lines, "")
+		}
+	}
+	return strings.Join(append(lines, bottom...), "\n")
+}
+
+func (m Model) RenderContextTreeView() string {
+	rows := m.contextTreeRows()
+	var content strings.Builder
+	content.WriteString("Contexts:\n\n")
+	for i, context := range rows {
+		marker := "  "
+		if context == m.CurrentContext {
+			marker = "• "
+		}
+		branch := "  "
+		if m.TreeCollapsed[context] && len(m.subContexts(context)) > 0 {
+			branch = "▸ "
+		} else if i+1 < len(rows) && isSubContext(rows[i+1], context) {
+			branch = "▾ "
+		}
+		open, total, _ := m.contextCounts(context)
+		counts := fmt.Sprintf(" %d/%d", open, total)
+		if m.ContextMeta[context].Aggregate {
+			counts += " +sub"
+		}
+		line := marker + strings.Repeat("  ", contextDepth(context)) + branch + contextLeaf(context)
+		if i == m.TreeIndex {
+			content.WriteString(m.Theme.SelectedTask.Render(line+counts) + "\n")
+		} else {
+			content.WriteString(m.contextStyle(context).Render(line) + m.Theme.Muted.Render(counts) + "\n")
+		}
+	}
+	content.WriteString("\n" + m.Theme.Muted.Render("enter select • ←/→ collapse/expand • space toggle • esc cancel"))
+	return lipgloss.Place(m.WindowWidth, m.WindowHeight, lipgloss.Center, lipgloss.Center, m.Theme.Input.Render(content.String()))
+}
+
+func (m Model) RenderNotesInputView() string {
+	content := m.Theme.Input.Render(lipgloss.JoinVertical(lipgloss.Left,
+		fmt.Sprintf("Notes for: %s", m.GetCurrentTask().Task),
+		"",
+		m.NotesInput.View(),
+		"",
+		m.Theme.Muted.Render("ctrl+s save • esc cancel"),
+	))
+	return lipgloss.Place(m.WindowWidth, m.WindowHeight, lipgloss.Center, lipgloss.Center, content)
+}
+
 func (m Model) RenderDateInputView() string {
 	var content strings.Builder
 	content.WriteString("Set due date (YYYY-MM-DD):\n\n")
@@ -181,12 +362,12 @@ func (m Model) RenderDateInputView() string {
 	}
 	for i, input := range inputs {
 		if i == m.DateInputIndex {
-			content.WriteString(selectedTaskStyle.Render(input) + "\n")
+			content.WriteString(m.Theme.SelectedTask.Render(input) + "\n")
 		} else {
 			content.WriteString(input + "\n")
 		}
 	}
-	return inputStyle.Render(content.String())
+	return m.Theme.Input.Render(content.String())
 }
 
 func (m Model) RenderRemoveTagView() string {
@@ -200,86 +381,108 @@ func (m Model) RenderRemoveTagView() string {
 		}
 		line := fmt.Sprintf("%s %s", checkbox, tag)
 		if i == m.RemoveTagIndex {
-			content.WriteString(selectedTaskStyle.Render(line) + "\n")
+			content.WriteString(m.Theme.SelectedTask.Render(line) + "\n")
 		} else {
 			content.WriteString(line + "\n")
 		}
 	}
-	return inputStyle.Render(content.String())
+	return m.Theme.Input.Render(content.String())
+}
+
+func (m *Model) kanbanTitle() string {
+	title := "Kanban View"
+	if m.KanbanLayout == KanbanByStatus {
+		title = fmt.Sprintf("Kanban: %s by status", m.CurrentContext)
+	}
+	if m.MovingMode {
+		return m.Theme.Title.Render(title + " (←/→/↑/↓ move card, m/esc to drop)")
+	}
+	return m.Theme.Title.Render(title + " (←/→/↑/↓ select, m to grab, tab to regroup, esc to return)")
+}
+
+func (m *Model) renderKanbanCard(task Task, focused, grabbed bool) string {
+	bullet := "• "
+	if task.Checked {
+		bullet = "✓ "
+	} else if m.StatusIndex(task) > 0 {
+		bullet = "~ "
+	}
+	text := task.Task
+	if task.Notes != "" {
+		text += " ✎"
+	}
+	if len(task.Tags) > 0 {
+		text += " > " + strings.Join(task.Tags, ", ")
+	}
+	if task.DueDate != "" {
+		text += fmt.Sprintf(" [Due: %s]", m.Settings.FormatDate(task.DueDate))
+	}
+
+	priority := m.priorityMarker(task)
+
+	style := lipgloss.NewStyle().Width(m.Settings.KanbanColumnWidth - 2 - lipgloss.Width(bullet) - lipgloss.Width(priority))
+	if task.Checked {
+		style = style.Inherit(m.Theme.CompletedTask)
+	}
+	if focused {
+		style = style.Inherit(m.Theme.Highlight)
+	}
+	if grabbed {
+		style = style.Bold(true)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, bullet, priority, style.Render(text))
 }
 
 func (m Model) RenderKanbanView() string {
 	var content strings.Builder
-	title := titleStyle.Render("Kanban View (←/→/↑/↓ scroll, esc to return)")
+	title := m.kanbanTitle()
 	content.WriteString(title + "\n")
 
-	if len(m.Contexts) == 0 {
+	columns := m.kanbanColumns()
+	if len(columns) == 0 {
 		content.WriteString("No contexts available.\n")
-		return baseStyle.Render(content.String())
+		content.WriteString(strings.Join(m.kanbanFooterLines(), "\n"))
+		return m.Theme.Base.Render(content.String())
 	}
 
-	const (
-		fixedColWidth  = 35
-		separatorWidth = 3
-	)
-
-	numVisibleCols := m.WindowWidth / (fixedColWidth + separatorWidth)
-	if numVisibleCols < 1 {
-		numVisibleCols = 1
-	}
+	numVisibleCols := m.kanbanVisibleCols()
 
-	if m.KanbanScrollX > len(m.Contexts)-numVisibleCols {
-		m.KanbanScrollX = max(0, len(m.Contexts)-numVisibleCols)
+	if m.KanbanScrollX > len(columns)-numVisibleCols {
+		m.KanbanScrollX = max(0, len(columns)-numVisibleCols)
 	}
 	if m.KanbanScrollX < 0 {
 		m.KanbanScrollX = 0
 	}
 
 	startCol := m.KanbanScrollX
-	endCol := min(startCol+numVisibleCols, len(m.Contexts))
-	visibleContexts := m.Contexts[startCol:endCol]
+	endCol := min(startCol+numVisibleCols, len(columns))
 
-	columnStyle := lipgloss.NewStyle().Width(fixedColWidth).Padding(0, 1)
-	taskTextStyle := lipgloss.NewStyle().Width(fixedColWidth - 2)
+	columnStyle := lipgloss.NewStyle().Width(m.kanbanColumnStride()).Padding(0, 1)
 
-	var columns []string
-	for _, context := range visibleContexts {
-		var column strings.Builder
-		header := contextStyle.Render(context)
-		column.WriteString(header + "\n")
-		column.WriteString(strings.Repeat("─", fixedColWidth) + "\n")
+	var rendered []string
+	for c := startCol; c < endCol; c++ {
+		column := columns[c]
+		var body strings.Builder
+		header := m.contextStyle(column.Context).Render(column.Title)
+		if c == m.KanbanCol {
+			header = m.contextStyle(column.Context).Underline(true).Render(column.Title)
+		}
+		body.WriteString(header + "\n")
+		body.WriteString(strings.Repeat("─", m.Settings.KanbanColumnWidth-2) + "\n")
 
-		tasks := m.GetTasksForContext(context)
-		for _, task := range tasks {
-			var taskLine strings.Builder
-			if task.Checked {
-				taskLine.WriteString("✓ ")
-			} else {
-				taskLine.WriteString("• ")
-			}
-			fullTaskText := task.Task
-			if len(task.Tags) > 0 {
-				fullTaskText += " > " + strings.Join(task.Tags, ", ")
-			}
-			if task.DueDate != "" {
-				fullTaskText += fmt.Sprintf(" [Due: %s]", task.DueDate)
-			}
-			wrappedText := taskTextStyle.Render(fullTaskText)
-			if task.Checked {
-				taskLine.WriteString(completedTaskStyle.Render(wrappedText))
-			} else {
-				taskLine.WriteString(wrappedText)
-			}
-			column.WriteString(taskLine.String() + "\n")
+		for r, task := range column.Tasks {
+			focused := c == m.KanbanCol && r == m.KanbanRow
+			body.WriteString(m.renderKanbanCard(task, focused, focused && m.MovingMode) + "\n")
 		}
-		columns = append(columns, columnStyle.Render(column.String()))
+		rendered = append(rendered, columnStyle.Render(body.String()))
 	}
 
-	board := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	board := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
 	boardLines := strings.Split(board, "\n")
 
 	top := m.KanbanScrollY
-	bottom := top + m.WindowHeight - lipgloss.Height(title) - 1
+	bottom := top + m.kanbanBoardHeight()
 	if top < 0 {
 		top = 0
 	}
@@ -291,16 +494,16 @@ func (m Model) RenderKanbanView() string {
 		m.KanbanScrollY = top
 	}
 
-	visibleLines := boardLines[top:bottom]
+	visibleLines := append(boardLines[top:bottom:bottom], m.kanbanFooterLines()...)
 	content.WriteString(strings.Join(visibleLines, "\n"))
 
-	return baseStyle.Render(content.String())
+	return m.Theme.Base.Render(content.String())
 }
 
 func (m Model) RenderStatsView() string {
 	var content strings.Builder
 
-	content.WriteString(titleStyle.Render("Statistics (ESC to return)") + "\n\n")
+	content.WriteString(m.Theme.Title.Render("Statistics (ESC to return)") + "\n\n")
 
 	total := len(m.Tasks)
 	completed := 0
@@ -315,11 +518,33 @@ func (m Model) RenderStatsView() string {
 		completionRate = float64(completed) / float64(total) * 100
 	}
 
+	dueThisWeek, overdue := 0, 0
+	now := time.Now()
+	today := now.Format(time.DateOnly)
+	weekStart, weekEnd := m.Settings.WeekBounds(now)
+	for _, task := range m.Tasks {
+		if task.Checked || task.DueDate == "" {
+			continue
+		}
+		if task.DueDate < today {
+			overdue++
+		}
+		if due, err := time.ParseInLocation(time.DateOnly, task.DueDate, now.Location()); err == nil && !due.Before(weekStart) && due.Before(weekEnd) {
+			dueThisWeek++
+		}
+	}
+
 	content.WriteString(fmt.Sprintf("Total Tasks: %d\n", total))
-	content.WriteString(fmt.Sprintf("Completed: %d (%.1f%%)\n\n", completed, completionRate))
+	content.WriteString(fmt.Sprintf("Completed: %d (%.1f%%)\n", completed, completionRate))
+	content.WriteString(fmt.Sprintf("Due this week: %d\n", dueThisWeek))
+	content.WriteString(fmt.Sprintf("Overdue: %d\n\n", overdue))
 
 	content.WriteString("Context Statistics:\n")
-	for _, context := range m.Contexts {
+	for _, context := range treeOrder(m.Contexts, func(string) bool { return true }) {
+		label := strings.Repeat("  ", contextDepth(context)) + m.contextStyle(context).Render(contextLeaf(context))
+		if m.ContextMeta[context].Archived {
+			label += m.Theme.Muted.Render(" (archived)")
+		}
 		tasks := m.GetTasksForContext(context)
 		ctxTotal := len(tasks)
 		ctxCompleted := 0
@@ -335,8 +560,8 @@ func (m Model) RenderStatsView() string {
 		}
 
 		content.WriteString(fmt.Sprintf("  %s: %d/%d (%.1f%%)\n",
-			contextStyle.Render(context), ctxCompleted, ctxTotal, ctxRate))
+			label, ctxCompleted, ctxTotal, ctxRate))
 	}
 
-	return baseStyle.Render(content.String())
+	return m.Theme.Base.Render(content.String())
 }
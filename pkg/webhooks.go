@@ -0,0 +1,317 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Outgoing webhooks. Saving queues a JSON POST for every change and
+// configured URL in a queue file in the state directory. The TUI delivers the
+// queue in the background and CLI commands make one attempt before exiting,
+// so events recorded while offline go out later. Failed deliveries are
+// retried with exponential backoff, for a few hours at most; a 4xx response
+// other than 408 or 429 is final and drops the delivery. The queue is capped,
+// dropping the oldest deliveries when a receiver has been down for long.
+
+const (
+	webhookTimeout     = 10 * time.Second
+	webhookMinBackoff  = 5 * time.Second
+	webhookMaxBackoff  = time.Hour
+	webhookMaxAttempts = 12 // about three and a half hours of retries
+	webhookMaxQueue    = 1000
+)
+
+var webhookEvents = map[string]string{HookAdd: "add", HookModify: "edit", HookComplete: "complete", HookDelete: "delete"}
+
+// WebhookPayload is the body POSTed for an event.
+type WebhookPayload struct {
+	Event   string         `json:"event"` // add, edit, complete or delete
+	Time    time.Time      `json:"time"`
+	File    string         `json:"file"`
+	Task    Task           `json:"task"`          // as saved; deleted tasks as they were
+	Old     *Task          `json:"old,omitempty"` // the task before an edit or completion
+	Context WebhookContext `json:"context"`
+}
+
+// WebhookContext describes the context of the task in a payload.
+type WebhookContext struct {
+	Name string `json:"name"`
+	ContextMeta
+}
+
+// webhookDelivery is a POST waiting in the queue.
+type webhookDelivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// WebhookQueuePath returns the file holding undelivered webhooks.
+func WebhookQueuePath() string {
+	return filepath.Join(StateDir(), "webhooks.json")
+}
+
+// webhookQueueMu serialises changes to the queue file between saves and
+// background deliveries.
+var webhookQueueMu sync.Mutex
+
+func loadWebhookQueue() ([]webhookDelivery, error) {
+	data, err := os.ReadFile(WebhookQueuePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var queue []webhookDelivery
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("%s: %w", WebhookQueuePath(), err)
+	}
+	return queue, nil
+}
+
+// updateWebhookQueue replaces the queue with what update makes of it.
+func updateWebhookQueue(update func([]webhookDelivery) []webhookDelivery) error {
+	webhookQueueMu.Lock()
+	defer webhookQueueMu.Unlock()
+	queue, err := loadWebhookQueue()
+	if err != nil {
+		return err
+	}
+	queue = update(queue)
+	if len(queue) == 0 {
+		if err := os.Remove(WebhookQueuePath()); !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(StateDir(), 0755); err != nil {
+		return err
+	}
+	tmp := WebhookQueuePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, WebhookQueuePath())
+}
+
+// WebhooksPending reports whether deliveries are waiting in the queue.
+func WebhooksPending() bool {
+	webhookQueueMu.Lock()
+	defer webhookQueueMu.Unlock()
+	queue, _ := loadWebhookQueue()
+	return len(queue) > 0
+}
+
+// queueWebhooks adds a delivery per change and webhook URL to the queue.
+func (m *Model) queueWebhooks(changes []taskChange) {
+	if len(m.Settings.Webhooks) == 0 || len(changes) == 0 {
+		return
+	}
+	now := time.Now()
+	var deliveries []webhookDelivery
+	for _, change := range changes {
+		payload := WebhookPayload{
+			Event: webhookEvents[change.Event],
+			Time:  now,
+			File:  m.ConfigFilePath,
+			Old:   change.Old,
+		}
+		if change.New != nil {
+			payload.Task = *change.New
+		} else {
+			payload.Task, payload.Old = *change.Old, nil
+		}
+		payload.Context = WebhookContext{Name: payload.Task.Context, ContextMeta: m.ContextMeta[payload.Task.Context]}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			continue
+		}
+		for _, url := range m.Settings.Webhooks {
+			deliveries = append(deliveries, webhookDelivery{
+				ID:          newTaskUID(),
+				URL:         url,
+				Event:       payload.Event,
+				Payload:     data,
+				NextAttempt: now,
+			})
+		}
+	}
+	dropped := 0
+	err := updateWebhookQueue(func(queue []webhookDelivery) []webhookDelivery {
+		queue = append(queue, deliveries...)
+		dropped = max(0, len(queue)-webhookMaxQueue)
+		return queue[dropped:]
+	})
+	if err != nil {
+		m.ErrorMessage = fmt.Sprintf("Could not queue webhooks: %v", err)
+		return
+	}
+	if dropped > 0 {
+		m.ErrorMessage = fmt.Sprintf("Webhook queue full: dropped the %d oldest deliveries", dropped)
+	}
+	m.WebhooksQueued = true
+}
+
+// webhookBackoff is the wait before retrying after the given number of
+// failed attempts.
+func webhookBackoff(attempts int) time.Duration {
+	wait := webhookMinBackoff
+	for i := 1; i < attempts && wait < webhookMaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, webhookMaxBackoff)
+}
+
+// postWebhook sends a delivery. It reports whether a failure is worth
+// retrying.
+func postWebhook(client *http.Client, d webhookDelivery) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "srn-todo")
+	req.Header.Set("X-Srn-Todo-Event", d.Event)
+	req.Header.Set("X-Srn-Todo-Delivery", d.ID)
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return false, fmt.Errorf("%s", resp.Status)
+	}
+	return true, fmt.Errorf("%s", resp.Status)
+}
+
+// WebhookResult reports a round of deliveries.
+type WebhookResult struct {
+	Delivered int
+	Failed    []string      // one line per failed attempt
+	RetryIn   time.Duration // until the next queued delivery is due, 0 if none is left
+}
+
+// DeliverWebhooks makes one attempt at every delivery that is due.
+func DeliverWebhooks(client *http.Client, now time.Time) WebhookResult {
+	var result WebhookResult
+	webhookQueueMu.Lock()
+	queue, err := loadWebhookQueue()
+	webhookQueueMu.Unlock()
+	if err != nil {
+		result.Failed = append(result.Failed, err.Error())
+		return result
+	}
+
+	done := make(map[string]bool)
+	retried := make(map[string]webhookDelivery)
+	for _, d := range queue {
+		if d.NextAttempt.After(now) {
+			continue
+		}
+		retry, err := postWebhook(client, d)
+		switch {
+		case err == nil:
+			result.Delivered++
+			done[d.ID] = true
+		case retry && d.Attempts+1 >= webhookMaxAttempts:
+			done[d.ID] = true
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v (dropped after %d attempts)", d.URL, err, webhookMaxAttempts))
+		case retry:
+			d.Attempts++
+			d.NextAttempt = now.Add(webhookBackoff(d.Attempts))
+			d.LastError = err.Error()
+			retried[d.ID] = d
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v (retrying in %s)", d.URL, err, webhookBackoff(d.Attempts)))
+		default:
+			done[d.ID] = true
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v (dropped)", d.URL, err))
+		}
+	}
+
+	err = updateWebhookQueue(func(queue []webhookDelivery) []webhookDelivery {
+		queue = slices.DeleteFunc(queue, func(d webhookDelivery) bool { return done[d.ID] })
+		for i, d := range queue {
+			if r, ok := retried[d.ID]; ok {
+				queue[i] = r
+			}
+		}
+		for _, d := range queue {
+			if wait := max(time.Second, d.NextAttempt.Sub(now)); result.RetryIn == 0 || wait < result.RetryIn {
+				result.RetryIn = wait
+			}
+		}
+		return queue
+	})
+	if err != nil {
+		result.Failed = append(result.Failed, err.Error())
+	}
+	return result
+}
+
+// webhookResultMsg carries the result of a background delivery round.
+type webhookResultMsg WebhookResult
+
+// webhookRetryMsg asks for another delivery round; at tells the retry the
+// model is waiting for from ones that were superseded.
+type webhookRetryMsg struct{ at time.Time }
+
+// deliverWebhooksCmd delivers the queue in the background.
+func deliverWebhooksCmd() tea.Cmd {
+	return func() tea.Msg {
+		return webhookResultMsg(DeliverWebhooks(http.DefaultClient, time.Now()))
+	}
+}
+
+// updateWebhooks handles the webhook messages and starts a delivery round
+// when saving queued new deliveries. Only one retry is scheduled at a time:
+// a round wanting an earlier one supersedes it.
+func (m Model) updateWebhooks(msg tea.Msg, cmd tea.Cmd) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case webhookResultMsg:
+		m.WebhookSending = false
+		if len(msg.Failed) > 0 && m.ErrorMessage == "" {
+			m.ErrorMessage = "Webhook: " + msg.Failed[0]
+		}
+		if at := time.Now().Add(msg.RetryIn); msg.RetryIn > 0 && (m.WebhookRetryAt.IsZero() || at.Before(m.WebhookRetryAt)) {
+			m.WebhookRetryAt = at
+			cmd = tea.Tick(msg.RetryIn, func(time.Time) tea.Msg { return webhookRetryMsg{at: at} })
+		}
+	case webhookRetryMsg:
+		if !msg.at.Equal(m.WebhookRetryAt) {
+			break
+		}
+		m.WebhookRetryAt = time.Time{}
+		m.WebhooksQueued = true
+	}
+	if m.WebhooksQueued && !m.WebhookSending {
+		m.WebhooksQueued = false
+		m.WebhookSending = true
+		cmd = tea.Batch(cmd, deliverWebhooksCmd())
+	}
+	return m, cmd
+}
@@ -0,0 +1,248 @@
+package todo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer answers each POST with the next status in statuses, the
+// last one repeating, and records the requests.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+		w.WriteHeader(s.statuses[min(len(s.requests), len(s.statuses))-1])
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// webhookModel returns testModel saving to an isolated home and posting to
+// url.
+func webhookModel(t *testing.T, url string) *Model {
+	t.Helper()
+	m := hookModel(t)
+	m.Settings.Webhooks = []string{url}
+	m.ContextMeta = map[string]ContextMeta{"Work": {Description: "Day job"}}
+	return m
+}
+
+func webhookQueue(t *testing.T) []webhookDelivery {
+	t.Helper()
+	queue, err := loadWebhookQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return queue
+}
+
+func TestWebhookPayload(t *testing.T) {
+	srv := newWebhookServer(t, http.StatusNoContent)
+	m := webhookModel(t, srv.URL)
+
+	m.AddTask("New task")
+	added := m.Tasks[len(m.Tasks)-1]
+	m.SaveConfig()
+	old := m.Tasks[0]
+	m.Tasks[0].Checked = true
+	completed := m.Tasks[0]
+	oldMilk := m.Tasks[1]
+	m.Tasks[1].Task = "Buy oat milk"
+	m.Tasks = m.Tasks[:len(m.Tasks)-1]
+	m.SaveConfig()
+
+	result := DeliverWebhooks(srv.Client(), time.Now())
+	if result.Delivered != 4 || len(result.Failed) != 0 || result.RetryIn != 0 {
+		t.Fatalf("result %+v", result)
+	}
+	if WebhooksPending() {
+		t.Error("delivered webhooks still queued")
+	}
+
+	var payloads []WebhookPayload
+	for i, body := range srv.bodies {
+		var p WebhookPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatalf("body %d: %v\n%s", i, err, body)
+		}
+		payloads = append(payloads, p)
+		r := srv.requests[i]
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+			r.Header.Get("X-Srn-Todo-Event") != p.Event || r.Header.Get("X-Srn-Todo-Delivery") == "" {
+			t.Errorf("request %d: %s %v", i, r.Method, r.Header)
+		}
+	}
+	want := []struct {
+		event string
+		task  Task
+		old   *Task
+	}{
+		{"add", added, nil},
+		{"complete", completed, &old},
+		{"edit", m.Tasks[1], &oldMilk},
+		{"delete", added, nil},
+	}
+	for i, w := range want {
+		p := payloads[i]
+		if p.Event != w.event || !tasksEqual(p.Task, w.task) || p.File != m.ConfigFilePath || p.Time.IsZero() {
+			t.Errorf("payload %d: %+v, want %s of %+v", i, p, w.event, w.task)
+		}
+		if w.old != nil && (p.Old == nil || !tasksEqual(*p.Old, *w.old)) {
+			t.Errorf("payload %d old = %+v, want %+v", i, p.Old, w.old)
+		}
+		if w.old == nil && p.Old != nil {
+			t.Errorf("payload %d has old %+v", i, p.Old)
+		}
+		if p.Context.Name != p.Task.Context {
+			t.Errorf("payload %d context %q, task in %q", i, p.Context.Name, p.Task.Context)
+		}
+	}
+	if payloads[1].Context.Description != "Day job" {
+		t.Errorf("context = %+v", payloads[1].Context)
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		delivered bool // by the end
+		retries   int  // failed attempts that are retried
+		dropped   string
+	}{
+		{name: "2xx", statuses: []int{200}, delivered: true},
+		{name: "5xx retries with backoff", statuses: []int{500, 502, 503, 201}, delivered: true, retries: 3},
+		{name: "408 retries", statuses: []int{408, 200}, delivered: true, retries: 1},
+		{name: "429 retries", statuses: []int{429, 200}, delivered: true, retries: 1},
+		{name: "4xx drops", statuses: []int{404}, dropped: "404 Not Found (dropped)"},
+		{name: "gives up", statuses: []int{500}, retries: webhookMaxAttempts - 1, dropped: "(dropped after 12 attempts)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newWebhookServer(t, tt.statuses...)
+			m := webhookModel(t, srv.URL)
+			m.AddTask("Task")
+			m.SaveConfig()
+
+			now := time.Now()
+			for attempt := 1; ; attempt++ {
+				result := DeliverWebhooks(srv.Client(), now)
+				if attempt <= tt.retries {
+					wait := webhookBackoff(attempt)
+					if len(result.Failed) != 1 || !strings.HasSuffix(result.Failed[0], "(retrying in "+wait.String()+")") || result.RetryIn != wait {
+						t.Fatalf("attempt %d: %+v", attempt, result)
+					}
+					if queue := webhookQueue(t); len(queue) != 1 || queue[0].Attempts != attempt || queue[0].LastError == "" {
+						t.Fatalf("attempt %d: queue %+v", attempt, queue)
+					}
+					// Nothing is due before the backoff is over.
+					if result := DeliverWebhooks(srv.Client(), now.Add(wait-time.Second)); result.Delivered != 0 || len(result.Failed) != 0 {
+						t.Fatalf("attempt %d: delivered early: %+v", attempt, result)
+					}
+					now = now.Add(wait)
+					continue
+				}
+				if tt.delivered != (result.Delivered == 1) {
+					t.Errorf("attempt %d: %+v", attempt, result)
+				}
+				if tt.dropped != "" && (len(result.Failed) != 1 || !strings.HasSuffix(result.Failed[0], tt.dropped)) {
+					t.Errorf("attempt %d: failed %q, want %q", attempt, result.Failed, tt.dropped)
+				}
+				if result.RetryIn != 0 || WebhooksPending() {
+					t.Errorf("attempt %d: still queued, retry in %s", attempt, result.RetryIn)
+				}
+				if srv.count() != attempt {
+					t.Errorf("server got %d requests in %d attempts", srv.count(), attempt)
+				}
+				return
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	want := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second}
+	for i, w := range want {
+		if got := webhookBackoff(i + 1); got != w {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+	if got := webhookBackoff(100); got != webhookMaxBackoff {
+		t.Errorf("webhookBackoff(100) = %s", got)
+	}
+}
+
+func TestWebhookQueueIsCapped(t *testing.T) {
+	m := webhookModel(t, "http://127.0.0.1:0")
+	m.Settings.Webhooks = []string{"http://a.invalid", "http://b.invalid"}
+	for range webhookMaxQueue/2 + 1 {
+		m.AddTask("Task")
+	}
+	first := m.Tasks[3].UID
+	m.SaveConfig()
+	queue := webhookQueue(t)
+	if len(queue) != webhookMaxQueue {
+		t.Fatalf("queue holds %d deliveries", len(queue))
+	}
+	if strings.Contains(string(queue[0].Payload), first) {
+		t.Error("oldest deliveries kept")
+	}
+	if !strings.Contains(m.ErrorMessage, "dropped the 2 oldest") {
+		t.Errorf("ErrorMessage = %q", m.ErrorMessage)
+	}
+}
+
+func TestWebhookRetriesAreNotMultiplied(t *testing.T) {
+	m := Model{}
+	m, cmd := m.updateWebhooks(webhookResultMsg{RetryIn: time.Minute}, nil)
+	if cmd == nil || m.WebhookRetryAt.IsZero() {
+		t.Fatal("no retry scheduled")
+	}
+	scheduled := m.WebhookRetryAt
+
+	// Another failed round doesn't add a second timer, unless it needs an
+	// earlier one.
+	m, cmd = m.updateWebhooks(webhookResultMsg{RetryIn: time.Hour}, nil)
+	if cmd != nil || !m.WebhookRetryAt.Equal(scheduled) {
+		t.Errorf("second retry scheduled for %s", m.WebhookRetryAt)
+	}
+	m, cmd = m.updateWebhooks(webhookResultMsg{RetryIn: time.Second}, nil)
+	if cmd == nil || !m.WebhookRetryAt.Before(scheduled) {
+		t.Fatal("earlier retry not scheduled")
+	}
+
+	// The superseded timer firing does nothing; the current one starts a
+	// round.
+	m, cmd = m.updateWebhooks(webhookRetryMsg{at: scheduled}, nil)
+	if cmd != nil || m.WebhooksQueued || m.WebhookSending {
+		t.Error("superseded retry started a round")
+	}
+	m, cmd = m.updateWebhooks(webhookRetryMsg{at: m.WebhookRetryAt}, nil)
+	if cmd == nil || !m.WebhookSending || !m.WebhookRetryAt.IsZero() {
+		t.Error("retry did not start a round")
+	}
+}